@@ -0,0 +1,6 @@
+//go:build !no_clickhouse
+
+package konsume
+
+// The ClickHouse driver is built in unless konsume is built with the no_clickhouse tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/clickhouse"
@@ -0,0 +1,6 @@
+//go:build !no_elasticsearch
+
+package konsume
+
+// The Elasticsearch driver is built in unless konsume is built with the no_elasticsearch tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/elasticsearch"
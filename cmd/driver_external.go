@@ -0,0 +1,6 @@
+//go:build !no_external
+
+package konsume
+
+// The driver of external plugin processes is built in unless konsume is built with the no_external tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/external"
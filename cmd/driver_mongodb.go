@@ -0,0 +1,6 @@
+//go:build !no_mongodb
+
+package konsume
+
+// The MongoDB driver is built in unless konsume is built with the no_mongodb tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/mongodb"
@@ -0,0 +1,6 @@
+//go:build !no_mysql
+
+package konsume
+
+// The MySQL driver is built in unless konsume is built with the no_mysql tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/mysql"
@@ -0,0 +1,6 @@
+//go:build !no_postgresql
+
+package konsume
+
+// The PostgreSQL driver is built in unless konsume is built with the no_postgresql tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/postgresql"
@@ -0,0 +1,6 @@
+//go:build !no_redis
+
+package konsume
+
+// The Redis driver is built in unless konsume is built with the no_redis tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/redis"
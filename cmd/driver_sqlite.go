@@ -0,0 +1,6 @@
+//go:build !no_sqlite
+
+package konsume
+
+// The SQLite driver is built in unless konsume is built with the no_sqlite tag
+import _ "github.com/bugrakocabay/konsume/pkg/database/sqlite"
@@ -1,6 +1,7 @@
 package konsume
 
 import (
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/health"
 	"github.com/bugrakocabay/konsume/pkg/metrics"
 	"github.com/bugrakocabay/konsume/pkg/queue"
 	"github.com/bugrakocabay/konsume/pkg/queue/activemq"
@@ -38,9 +40,16 @@ func Execute() {
 		slog.Error("Failed to initialize databases", "error", err)
 		return
 	}
+	if err = prepareDatabaseRoutes(cfg.Queues, databaseMap); err != nil {
+		slog.Error("Failed to prepare database routes", "error", err)
+		return
+	}
 	if cfg.Metrics != nil && cfg.Metrics.Enabled {
 		metrics.InitMetrics(cfg.Metrics)
 	}
+	if cfg.Health != nil && cfg.Health.Enabled {
+		health.InitHealth(cfg.Health)
+	}
 
 	go func() {
 		if err = runner.StartConsumers(cfg, consumerMap, providerMap, databaseMap); err != nil {
@@ -105,21 +114,44 @@ func initProviders(
 func initDatabases(cfg []*config.DatabaseConfig) (map[string]database.Database, error) {
 	dbMap := make(map[string]database.Database)
 	for _, dbConfig := range cfg {
-		db, err := database.LoadDatabasePlugin(dbConfig.Type)
+		db, err := database.New(dbConfig.Type)
 		if err != nil {
-			slog.Error("Failed to load database plugin", "type", dbConfig.Type, "error", err)
+			slog.Error("Failed to create database", "type", dbConfig.Type, "error", err)
 			return nil, err
 		}
+		if configurable, ok := db.(database.Configurable); ok {
+			if err = configurable.Configure(*dbConfig); err != nil {
+				slog.Error("Failed to configure database", "type", dbConfig.Type, "error", err)
+				return nil, err
+			}
+		}
 		err = connectDbRetry(db, *dbConfig)
 		if err != nil {
 			slog.Error("Failed to connect to database after retries", "type", dbConfig.Type, "error", err)
 			return nil, err
 		}
+		database.Monitor(*dbConfig, db)
 		dbMap[dbConfig.Name] = db
 	}
 	return dbMap, nil
 }
 
+// prepareDatabaseRoutes lets the databases that support it check or migrate their tables before consuming starts
+func prepareDatabaseRoutes(queues []*config.QueueConfig, dbMap map[string]database.Database) error {
+	for _, queueConfig := range queues {
+		for _, route := range queueConfig.DatabaseRoutes {
+			preparer, ok := dbMap[route.Provider].(database.RoutePreparer)
+			if !ok {
+				continue
+			}
+			if err := preparer.PrepareRoute(*route); err != nil {
+				return fmt.Errorf("database route %s: %w", route.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // connectDbRetry attempts to connect to the database with retry logic
 func connectDbRetry(db database.Database, dbConfig config.DatabaseConfig) error {
 	var err error
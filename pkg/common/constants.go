@@ -1,5 +1,7 @@
 package common
 
+import "time"
+
 const (
 	QueueSourceRabbitMQ = "rabbitmq"
 	QueueSourceKafka    = "kafka"
@@ -18,8 +20,113 @@ const (
 )
 
 const (
-	DatabaseTypePostgresql = "postgresql"
-	DatabaseTypeMongoDB    = "mongodb"
+	MessageFormatJSON     = "json"
+	MessageFormatXML      = "xml"
+	MessageFormatCSV      = "csv"
+	MessageFormatForm     = "form"
+	MessageFormatText     = "text"
+	MessageFormatAvro     = "avro"
+	MessageFormatProtobuf = "protobuf"
+)
+
+const (
+	MessageBodyKey  = "$body"
+	MessageItemsKey = "$items"
+	MessageTextKey  = "#text"
+	XMLAttrPrefix   = "@"
+)
+
+const (
+	SigningSchemeDigest   = "digest"
+	SigningSchemeStripe   = "stripe"
+	SigningSchemeAWSSigV4 = "aws-sigv4"
+)
+
+const (
+	SigningAlgorithmSHA256 = "sha256"
+	SigningAlgorithmSHA512 = "sha512"
+)
+
+const (
+	DatabaseTypePostgresql    = "postgresql"
+	DatabaseTypeMongoDB       = "mongodb"
+	DatabaseTypeMySQL         = "mysql"
+	DatabaseTypeSQLite        = "sqlite"
+	DatabaseTypeClickHouse    = "clickhouse"
+	DatabaseTypeElasticsearch = "elasticsearch"
+	DatabaseTypeRedis         = "redis"
+	DatabaseTypeExternal      = "external"
+)
+
+const (
+	DatabaseRouteModeInsert = "insert"
+	DatabaseRouteModeUpsert = "upsert"
+	DatabaseRouteModeUpdate = "update"
+	DatabaseRouteModeDelete = "delete"
+)
+
+const (
+	DatabaseRouteOnErrorRetry      = "retry"
+	DatabaseRouteOnErrorDeadLetter = "dead-letter"
+	DatabaseRouteOnErrorIgnore     = "ignore"
+)
+
+const (
+	ForEachMessagePrefix = "$message."
+	ForEachValueKey      = "$value"
+)
+
+const (
+	RedisCommandSet    = "set"
+	RedisCommandHSet   = "hset"
+	RedisCommandLPush  = "lpush"
+	RedisCommandXAdd   = "xadd"
+	RedisCommandIncrBy = "incrby"
+)
+
+const (
+	ExpectOnFailureRetry = "retry"
+	ExpectOnFailureFail  = "fail"
+)
+
+const (
+	DedupStoreMemory   = "memory"
+	DedupStoreRedis    = "redis"
+	DedupStoreDatabase = "database"
+)
+
+const (
+	DedupSourceBody     = "body"
+	DedupSourceMetadata = "metadata"
+)
+
+const (
+	MetadataMessageID    = "message-id"
+	MetadataHeaderPrefix = "header."
+)
+
+const (
+	RouteResponsesKey = "routes"
+)
+
+const (
+	DefaultBatchSize = 100
+	DefaultBatchWait = time.Second
+)
+
+const (
+	DefaultDatabaseHealthCheckInterval = 10 * time.Second
+	DefaultDatabaseReconnectTimeout    = 30 * time.Second
+	DefaultMongoDBTimeout              = 5 * time.Second
+)
+
+const (
+	DefaultSchemaRegistryTimeout = 10 * time.Second
+)
+
+const (
+	DefaultExternalHealthCheckInterval = 10 * time.Second
+	DefaultExternalMaxRestarts         = 5
 )
 
 const (
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+var (
+	invalidBatchSizeError          = errors.New("batch size must not be negative")
+	invalidBatchWaitError          = errors.New("batch wait must not be negative")
+	batchNotSupportedForRouteError = errors.New("batch is only supported for REST routes without capture")
+)
+
+// BatchConfig is the configuration for writing the data of multiple messages at once
+type BatchConfig struct {
+	// Size is the maximum amount of messages in a batch
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// Wait is the maximum duration to wait for a batch to fill up before it is written
+	Wait time.Duration `yaml:"wait,omitempty" json:"wait,omitempty"`
+}
+
+// validateBatch validates the BatchConfig struct and sets the default values
+func (b *BatchConfig) validateBatch() error {
+	if b.Size < 0 {
+		return invalidBatchSizeError
+	}
+	if b.Wait < 0 {
+		return invalidBatchWaitError
+	}
+	if b.Size == 0 {
+		slog.Debug("Batch size not defined, using default size", "size", common.DefaultBatchSize)
+		b.Size = common.DefaultBatchSize
+	}
+	if b.Wait == 0 {
+		slog.Debug("Batch wait not defined, using default wait", "wait", common.DefaultBatchWait)
+		b.Wait = common.DefaultBatchWait
+	}
+	return nil
+}
+
+// validateRouteBatch validates the batch of a route, which must be a REST route that does not capture its response
+func (route *RouteConfig) validateRouteBatch() error {
+	if route.Type != common.RouteTypeREST || len(route.Capture) > 0 {
+		return batchNotSupportedForRouteError
+	}
+	return route.Batch.validateBatch()
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	invalidFailureRatioError       = errors.New("circuit breaker failure ratio must be between 0 and 1")
+	invalidCircuitBreakerError     = errors.New("circuit breaker durations and counts must not be negative")
+	fallbackHasCircuitBreakerError = errors.New("fallback route can not define its own circuit breaker or fallback")
+)
+
+// CircuitBreakerConfig is the configuration for the circuit breaker of a route
+type CircuitBreakerConfig struct {
+	// FailureRatio is the ratio of failed requests in the window that opens the circuit, defaults to 0.5
+	FailureRatio float64 `yaml:"failure-ratio,omitempty" json:"failure-ratio,omitempty"`
+
+	// Window is the duration that the failure ratio is calculated over, defaults to 60 seconds
+	Window time.Duration `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// MinRequests is the minimum number of requests in the window before the circuit can open, defaults to 10
+	MinRequests int `yaml:"min-requests,omitempty" json:"min-requests,omitempty"`
+
+	// OpenDuration is the duration the circuit stays open before probing the route again, defaults to 30 seconds
+	OpenDuration time.Duration `yaml:"open-duration,omitempty" json:"open-duration,omitempty"`
+
+	// HalfOpenRequests is the number of successful probe requests needed to close the circuit, defaults to 1
+	HalfOpenRequests int `yaml:"half-open-requests,omitempty" json:"half-open-requests,omitempty"`
+
+	// Fallback is the route that messages are sent to while the circuit is open.
+	// When it is not defined, consumption of the queue is paused until the circuit is half-open
+	Fallback *RouteConfig `yaml:"fallback,omitempty" json:"fallback,omitempty"`
+}
+
+// validateCircuitBreaker validates the CircuitBreakerConfig struct and sets the default values
+func (c *CircuitBreakerConfig) validateCircuitBreaker() error {
+	if c.FailureRatio < 0 || c.FailureRatio > 1 {
+		return invalidFailureRatioError
+	}
+	if c.Window < 0 || c.OpenDuration < 0 || c.MinRequests < 0 || c.HalfOpenRequests < 0 {
+		return invalidCircuitBreakerError
+	}
+	if c.FailureRatio == 0 {
+		c.FailureRatio = 0.5
+	}
+	if c.Window == 0 {
+		c.Window = 60 * time.Second
+	}
+	if c.MinRequests == 0 {
+		c.MinRequests = 10
+	}
+	if c.OpenDuration == 0 {
+		c.OpenDuration = 30 * time.Second
+	}
+	if c.HalfOpenRequests == 0 {
+		c.HalfOpenRequests = 1
+	}
+	if c.Fallback != nil {
+		if c.Fallback.CircuitBreaker != nil {
+			return fallbackHasCircuitBreakerError
+		}
+		if err := c.Fallback.validateRoute(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -34,11 +34,17 @@ type Config struct {
 	// Metrics is the configuration for the metrics endpoint
 	Metrics *MetricsConfig `yaml:"metrics" json:"metrics"`
 
+	// Health is the configuration for the readiness endpoint
+	Health *HealthConfig `yaml:"health,omitempty" json:"health,omitempty"`
+
 	// Log is the format of the log
 	Log string `yaml:"log,omitempty" json:"log"`
 
 	// Databases is the configuration for the database connections
 	Databases []*DatabaseConfig `yaml:"databases" json:"databases"`
+
+	// HTTP is the configuration for the connection pool shared by the routes
+	HTTP *HTTPClientConfig `yaml:"http,omitempty" json:"http,omitempty"`
 }
 
 // LoadConfig loads the configuration from a file which can be either YAML or JSON.
@@ -113,6 +119,28 @@ func (c *Config) ValidateAll() error {
 		}
 	}
 
+	if c.HTTP != nil {
+		err := c.HTTP.validateHTTPClient()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, q := range c.Queues {
+		for _, r := range q.Routes {
+			err := r.applyHTTPClient(c.HTTP)
+			if err != nil {
+				return err
+			}
+			if r.CircuitBreaker != nil && r.CircuitBreaker.Fallback != nil {
+				err = r.CircuitBreaker.Fallback.applyHTTPClient(c.HTTP)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	if c.Metrics != nil {
 		err := c.Metrics.validateMetrics()
 		if err != nil {
@@ -120,6 +148,13 @@ func (c *Config) ValidateAll() error {
 		}
 	}
 
+	if c.Health != nil {
+		err := c.Health.validateHealth()
+		if err != nil {
+			return err
+		}
+	}
+
 	if c.Log == "" {
 		c.Log = "text"
 	}
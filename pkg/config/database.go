@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/bugrakocabay/konsume/pkg/common"
 )
@@ -12,6 +14,15 @@ var (
 	databaseTypeInvalidError                = errors.New("database type invalid")
 	databaseConnectionStringNotDefinedError = errors.New("database connection string not defined")
 	databaseDatabaseNotDefinedError         = errors.New("database database not defined")
+	invalidDatabasePoolSizeError            = errors.New("database pool size must not be negative")
+	invalidDatabasePoolDurationError        = errors.New("database pool durations must not be negative")
+	elasticsearchAuthConflictError          = errors.New("elasticsearch api-key and username can not be used together")
+	externalConfigNotDefinedError           = errors.New("external database external-config not defined")
+	externalCommandNotDefinedError          = errors.New("external database command not defined")
+	invalidExternalHealthCheckError         = errors.New("external database health-check-interval and max-restarts must not be negative")
+	invalidDatabaseHealthCheckError         = errors.New("database health-check-interval and reconnect-timeout must not be negative")
+	invalidMongoDBTimeoutError              = errors.New("mongodb timeout must not be negative")
+	invalidMongoDBWriteConcernError         = errors.New("mongodb write-concern must be majority, a tag or a number that is not negative")
 )
 
 // DatabaseConfig is the configuration for the database connections
@@ -30,6 +41,113 @@ type DatabaseConfig struct {
 
 	// Database is the database name for the database
 	Database string `yaml:"database,omitempty" json:"database,omitempty"`
+
+	// Pool is the configuration for the connection pool and statement timeout of the database
+	Pool *PoolConfig `yaml:"pool,omitempty" json:"pool,omitempty"`
+
+	// HealthCheckInterval is the interval of the checks whether the database can be reached, defaults to 10s
+	HealthCheckInterval time.Duration `yaml:"health-check-interval,omitempty" json:"health-check-interval,omitempty"`
+
+	// ReconnectTimeout is the maximum duration a write waits for the database to be reachable again
+	// after it became unavailable, defaults to 30s
+	ReconnectTimeout time.Duration `yaml:"reconnect-timeout,omitempty" json:"reconnect-timeout,omitempty"`
+
+	// MySQLConfig is the configuration for the TLS connection of a MySQL database
+	MySQLConfig *MySQLConfig `yaml:"mysql-config,omitempty" json:"mysql-config,omitempty"`
+
+	// ClickHouseConfig is the configuration for the inserts into a ClickHouse database
+	ClickHouseConfig *ClickHouseConfig `yaml:"clickhouse-config,omitempty" json:"clickhouse-config,omitempty"`
+
+	// ElasticsearchConfig is the configuration for the authentication and TLS of an Elasticsearch or OpenSearch cluster
+	ElasticsearchConfig *ElasticsearchConfig `yaml:"elasticsearch-config,omitempty" json:"elasticsearch-config,omitempty"`
+
+	// ExternalConfig is the configuration of the plugin process of an external database
+	ExternalConfig *ExternalConfig `yaml:"external-config,omitempty" json:"external-config,omitempty"`
+
+	// MongoDBConfig is the configuration for the writes into a MongoDB database
+	MongoDBConfig *MongoDBConfig `yaml:"mongodb-config,omitempty" json:"mongodb-config,omitempty"`
+}
+
+// PoolConfig is the configuration for the connection pool and statement timeout of a database
+type PoolConfig struct {
+	// MaxOpenConns is the maximum number of open connections, defaults to the default of the driver
+	MaxOpenConns int `yaml:"max-open-conns,omitempty" json:"max-open-conns,omitempty"`
+
+	// MaxIdleConns is the maximum number of idle connections, defaults to the default of the driver
+	MaxIdleConns int `yaml:"max-idle-conns,omitempty" json:"max-idle-conns,omitempty"`
+
+	// ConnMaxLifetime is the maximum amount of time a connection may be reused, defaults to 0 which means no limit
+	ConnMaxLifetime time.Duration `yaml:"conn-max-lifetime,omitempty" json:"conn-max-lifetime,omitempty"`
+
+	// ConnMaxIdleTime is the maximum amount of time a connection may be idle, defaults to 0 which means no limit
+	ConnMaxIdleTime time.Duration `yaml:"conn-max-idle-time,omitempty" json:"conn-max-idle-time,omitempty"`
+
+	// StatementTimeout is the maximum duration of a write, defaults to 0 which means no limit
+	StatementTimeout time.Duration `yaml:"statement-timeout,omitempty" json:"statement-timeout,omitempty"`
+}
+
+// MySQLConfig is the configuration for the TLS connection of a MySQL database, its connection pool is configured with Pool
+type MySQLConfig struct {
+	// TLS is the configuration for the TLS connection to the database
+	TLS *TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// ClickHouseConfig is the configuration for the inserts into a ClickHouse database
+type ClickHouseConfig struct {
+	// AsyncInsert enables asynchronous inserts, which are buffered and written by the server
+	AsyncInsert bool `yaml:"async-insert,omitempty" json:"async-insert,omitempty"`
+
+	// WaitForAsyncInsert makes an asynchronous insert wait until the data is written, defaults to true
+	WaitForAsyncInsert *bool `yaml:"wait-for-async-insert,omitempty" json:"wait-for-async-insert,omitempty"`
+}
+
+// ElasticsearchConfig is the configuration for the authentication and TLS of an Elasticsearch or OpenSearch cluster
+type ElasticsearchConfig struct {
+	// Username is the username of the basic authentication
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+
+	// Password is the password of the basic authentication
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+
+	// APIKey is the base64 encoded API key that is sent in the Authorization header
+	APIKey string `yaml:"api-key,omitempty" json:"api-key,omitempty"`
+
+	// TLS is the configuration for the TLS connection to the cluster
+	TLS *TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// MongoDBConfig is the configuration for the writes into a MongoDB database
+type MongoDBConfig struct {
+	// WriteConcern is the number of members that acknowledge a write, "majority" or the name of a tag set,
+	// defaults to the write concern of the connection string
+	WriteConcern string `yaml:"write-concern,omitempty" json:"write-concern,omitempty"`
+
+	// Journal makes writes wait until they are written to the journal
+	Journal *bool `yaml:"journal,omitempty" json:"journal,omitempty"`
+
+	// Timeout is the maximum duration of a write, defaults to the statement timeout of the pool or 5s
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// ParseTimestamps stores string values in RFC 3339 format, such as 2024-01-02T15:04:05Z, as dates
+	ParseTimestamps bool `yaml:"parse-timestamps,omitempty" json:"parse-timestamps,omitempty"`
+}
+
+// ExternalConfig is the configuration of the plugin process that writes the data of an external database
+type ExternalConfig struct {
+	// Command is the path of the plugin executable
+	Command string `yaml:"command" json:"command"`
+
+	// Args are the arguments the plugin is started with
+	Args []string `yaml:"args,omitempty" json:"args,omitempty"`
+
+	// Env are the environment variables the plugin is started with in addition to the environment of konsume
+	Env map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// HealthCheckInterval is the interval of the health checks of the plugin, defaults to 10s
+	HealthCheckInterval time.Duration `yaml:"health-check-interval,omitempty" json:"health-check-interval,omitempty"`
+
+	// MaxRestarts is the amount of times the plugin is restarted after it exited or failed a health check, defaults to 5
+	MaxRestarts *int `yaml:"max-restarts,omitempty" json:"max-restarts,omitempty"`
 }
 
 func validateDatabaseConfig(database *DatabaseConfig) error {
@@ -39,14 +157,129 @@ func validateDatabaseConfig(database *DatabaseConfig) error {
 	if len(database.Type) == 0 {
 		return databaseTypeNotDefinedError
 	}
-	if len(database.ConnectionString) == 0 {
+	// the connection string of an external database is passed to its plugin, which may not need one
+	if len(database.ConnectionString) == 0 && database.Type != common.DatabaseTypeExternal {
 		return databaseConnectionStringNotDefinedError
 	}
-	if database.Type != common.DatabaseTypePostgresql && database.Type != common.DatabaseTypeMongoDB {
+	if database.Type != common.DatabaseTypePostgresql &&
+		database.Type != common.DatabaseTypeMongoDB &&
+		database.Type != common.DatabaseTypeMySQL &&
+		database.Type != common.DatabaseTypeSQLite &&
+		database.Type != common.DatabaseTypeClickHouse &&
+		database.Type != common.DatabaseTypeElasticsearch &&
+		database.Type != common.DatabaseTypeRedis &&
+		database.Type != common.DatabaseTypeExternal {
 		return databaseTypeInvalidError
 	}
 	if database.Type == common.DatabaseTypeMongoDB && len(database.Database) == 0 {
 		return databaseDatabaseNotDefinedError
 	}
+	if database.Pool != nil {
+		if err := database.Pool.validatePool(); err != nil {
+			return err
+		}
+	}
+	if database.HealthCheckInterval < 0 || database.ReconnectTimeout < 0 {
+		return invalidDatabaseHealthCheckError
+	}
+	if database.HealthCheckInterval == 0 {
+		database.HealthCheckInterval = common.DefaultDatabaseHealthCheckInterval
+	}
+	if database.ReconnectTimeout == 0 {
+		database.ReconnectTimeout = common.DefaultDatabaseReconnectTimeout
+	}
+	if database.MySQLConfig != nil {
+		if err := database.MySQLConfig.validateMySQL(); err != nil {
+			return err
+		}
+	}
+	if database.ClickHouseConfig != nil {
+		database.ClickHouseConfig.validateClickHouse()
+	}
+	if database.ElasticsearchConfig != nil {
+		if err := database.ElasticsearchConfig.validateElasticsearch(); err != nil {
+			return err
+		}
+	}
+	if database.MongoDBConfig != nil {
+		if err := database.MongoDBConfig.validateMongoDB(); err != nil {
+			return err
+		}
+	}
+	if database.Type == common.DatabaseTypeExternal {
+		if database.ExternalConfig == nil {
+			return externalConfigNotDefinedError
+		}
+		if err := database.ExternalConfig.validateExternal(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePool validates the PoolConfig struct
+func (p *PoolConfig) validatePool() error {
+	if p.MaxOpenConns < 0 || p.MaxIdleConns < 0 {
+		return invalidDatabasePoolSizeError
+	}
+	if p.ConnMaxLifetime < 0 || p.ConnMaxIdleTime < 0 || p.StatementTimeout < 0 {
+		return invalidDatabasePoolDurationError
+	}
+	return nil
+}
+
+// validateMySQL validates the MySQLConfig struct and sets the default values
+func (m *MySQLConfig) validateMySQL() error {
+	if m.TLS != nil {
+		return m.TLS.validateTLS()
+	}
+	return nil
+}
+
+// validateClickHouse sets the default values of the ClickHouseConfig struct
+func (c *ClickHouseConfig) validateClickHouse() {
+	if c.WaitForAsyncInsert == nil {
+		wait := true
+		c.WaitForAsyncInsert = &wait
+	}
+}
+
+// validateMongoDB validates the MongoDBConfig struct, the timeout is resolved by the driver
+func (m *MongoDBConfig) validateMongoDB() error {
+	if m.Timeout < 0 {
+		return invalidMongoDBTimeoutError
+	}
+	if w, err := strconv.Atoi(m.WriteConcern); err == nil && w < 0 {
+		return invalidMongoDBWriteConcernError
+	}
+	return nil
+}
+
+// validateElasticsearch validates the ElasticsearchConfig struct
+func (e *ElasticsearchConfig) validateElasticsearch() error {
+	if e.APIKey != "" && e.Username != "" {
+		return elasticsearchAuthConflictError
+	}
+	if e.TLS != nil {
+		return e.TLS.validateTLS()
+	}
+	return nil
+}
+
+// validateExternal validates the ExternalConfig struct and sets the default values
+func (e *ExternalConfig) validateExternal() error {
+	if len(e.Command) == 0 {
+		return externalCommandNotDefinedError
+	}
+	if e.HealthCheckInterval < 0 || (e.MaxRestarts != nil && *e.MaxRestarts < 0) {
+		return invalidExternalHealthCheckError
+	}
+	if e.HealthCheckInterval == 0 {
+		e.HealthCheckInterval = common.DefaultExternalHealthCheckInterval
+	}
+	if e.MaxRestarts == nil {
+		maxRestarts := common.DefaultExternalMaxRestarts
+		e.MaxRestarts = &maxRestarts
+	}
 	return nil
 }
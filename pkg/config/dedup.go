@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+var (
+	invalidDedupStoreError                 = errors.New("invalid dedup store")
+	invalidDedupSourceError                = errors.New("invalid dedup source")
+	invalidDedupTTLError                   = errors.New("dedup ttl must not be negative")
+	dedupRedisConfigNotDefinedError        = errors.New("dedup redis config not defined")
+	dedupRedisAddressNotDefinedError       = errors.New("dedup redis address not defined")
+	dedupDatabaseConfigNotDefinedError     = errors.New("dedup database config not defined")
+	dedupDatabaseProviderDoesNotExistError = errors.New("dedup database provider does not exist in databases list")
+	dedupDatabaseProviderNotSupportedError = errors.New("dedup database store only supports postgresql")
+)
+
+// DedupConfig is the configuration for detecting and skipping the messages that are already processed
+type DedupConfig struct {
+	// Source is where the message ID is read from, either "body" or "metadata", defaults to "body"
+	Source string `yaml:"source,omitempty" json:"source,omitempty"`
+
+	// Field is the dot separated path of the message ID when the source is "body", the SHA-256 of the message is used
+	// when it is not defined. When the source is "metadata" it is the key of the delivery metadata, such as
+	// "header.x-request-id", defaults to "message-id"
+	Field string `yaml:"field,omitempty" json:"field,omitempty"`
+
+	// Store is the type of the store that keeps the processed IDs, either "memory", "redis" or "database", defaults to "memory"
+	Store string `yaml:"store,omitempty" json:"store,omitempty"`
+
+	// TTL is the duration that a processed ID is remembered, defaults to 24 hours
+	TTL time.Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// Size is the maximum number of IDs kept by the memory store, defaults to 10000
+	Size int `yaml:"size,omitempty" json:"size,omitempty"`
+
+	// IdempotencyHeader is the name of the header that the ID is forwarded in to the routes, such as "Idempotency-Key"
+	IdempotencyHeader string `yaml:"idempotency-header,omitempty" json:"idempotency-header,omitempty"`
+
+	// Redis is the configuration for the redis store
+	Redis *DedupRedisConfig `yaml:"redis,omitempty" json:"redis,omitempty"`
+
+	// Database is the configuration for the database store
+	Database *DedupDatabaseConfig `yaml:"database,omitempty" json:"database,omitempty"`
+}
+
+// DedupRedisConfig is the configuration for keeping the processed IDs in Redis
+type DedupRedisConfig struct {
+	// Address is the address of the Redis server, such as "localhost:6379"
+	Address string `yaml:"address" json:"address"`
+
+	// Password is the password of the Redis server
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+
+	// DB is the index of the Redis database
+	DB int `yaml:"db,omitempty" json:"db,omitempty"`
+
+	// Prefix is the prefix of the keys, defaults to "konsume:dedup:"
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+}
+
+// DedupDatabaseConfig is the configuration for keeping the processed IDs in a database table
+type DedupDatabaseConfig struct {
+	// Provider is the name of the database in databases list
+	Provider string `yaml:"provider" json:"provider"`
+
+	// Table is the name of the table, it is created if it does not exist, defaults to "konsume_dedup"
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
+}
+
+// validateDedup validates the DedupConfig struct and sets the default values
+func (d *DedupConfig) validateDedup(databases []*DatabaseConfig) error {
+	if d.Store == "" {
+		d.Store = common.DedupStoreMemory
+	}
+	switch d.Source {
+	case "":
+		d.Source = common.DedupSourceBody
+	case common.DedupSourceBody:
+	case common.DedupSourceMetadata:
+		if d.Field == "" {
+			d.Field = common.MetadataMessageID
+		}
+	default:
+		return invalidDedupSourceError
+	}
+	if d.TTL < 0 {
+		return invalidDedupTTLError
+	}
+	if d.TTL == 0 {
+		d.TTL = 24 * time.Hour
+	}
+
+	switch d.Store {
+	case common.DedupStoreMemory:
+		if d.Size <= 0 {
+			d.Size = 10000
+		}
+	case common.DedupStoreRedis:
+		if d.Redis == nil {
+			return dedupRedisConfigNotDefinedError
+		}
+		if len(d.Redis.Address) == 0 {
+			return dedupRedisAddressNotDefinedError
+		}
+		if d.Redis.Prefix == "" {
+			d.Redis.Prefix = "konsume:dedup:"
+		}
+	case common.DedupStoreDatabase:
+		if d.Database == nil {
+			return dedupDatabaseConfigNotDefinedError
+		}
+		var provider *DatabaseConfig
+		for _, database := range databases {
+			if database.Name == d.Database.Provider {
+				provider = database
+				break
+			}
+		}
+		if provider == nil {
+			return dedupDatabaseProviderDoesNotExistError
+		}
+		if provider.Type != common.DatabaseTypePostgresql {
+			return dedupDatabaseProviderNotSupportedError
+		}
+		if d.Database.Table == "" {
+			d.Database.Table = "konsume_dedup"
+		}
+	default:
+		return invalidDedupStoreError
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+var (
+	invalidExpectStatusError      = errors.New("invalid expected status, use a code, a range such as 200-299 or a class such as 2xx")
+	expectBodyPathNotDefinedError = errors.New("expected body assertion path not defined")
+	invalidExpectBodyMatchesError = errors.New("invalid regular expression in expected body assertion")
+	invalidExpectOnFailureError   = errors.New("invalid expectation on-failure, use retry or fail")
+)
+
+// ExpectConfig is the configuration of the conditions that a response must meet for a route to succeed
+type ExpectConfig struct {
+	// Status is the list of accepted status codes, ranges such as "200-299" or classes such as "2xx"
+	Status []string `yaml:"status,omitempty" json:"status,omitempty"`
+
+	// Headers is the list of headers that must be present in the response, an empty value only checks the presence
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Body is the list of assertions on the JSON response body
+	Body []*BodyAssertion `yaml:"body,omitempty" json:"body,omitempty"`
+
+	// OnFailure is the behaviour when an expectation fails, either "retry" or "fail", defaults to "retry"
+	OnFailure string `yaml:"on-failure,omitempty" json:"on-failure,omitempty"`
+}
+
+// BodyAssertion is an assertion on a value of the JSON response body
+type BodyAssertion struct {
+	// Path is the dot separated path of the value, such as "ok" or "data.items.0.id"
+	Path string `yaml:"path" json:"path"`
+
+	// Equals is the value that the value at the path must be equal to
+	Equals interface{} `yaml:"equals,omitempty" json:"equals,omitempty"`
+
+	// NotEquals is the value that the value at the path must not be equal to
+	NotEquals interface{} `yaml:"not-equals,omitempty" json:"not-equals,omitempty"`
+
+	// Matches is the regular expression that the value at the path must match
+	Matches string `yaml:"matches,omitempty" json:"matches,omitempty"`
+}
+
+// validateExpect validates the ExpectConfig struct and sets the default values
+func (e *ExpectConfig) validateExpect() error {
+	for _, status := range e.Status {
+		if _, _, err := util.ParseStatusRange(status); err != nil {
+			return invalidExpectStatusError
+		}
+	}
+	for _, assertion := range e.Body {
+		if len(assertion.Path) == 0 {
+			return expectBodyPathNotDefinedError
+		}
+		if len(assertion.Matches) > 0 {
+			if _, err := regexp.Compile(assertion.Matches); err != nil {
+				return invalidExpectBodyMatchesError
+			}
+		}
+	}
+	if e.OnFailure == "" {
+		e.OnFailure = common.ExpectOnFailureRetry
+	}
+	if e.OnFailure != common.ExpectOnFailureRetry && e.OnFailure != common.ExpectOnFailureFail {
+		return invalidExpectOnFailureError
+	}
+	return nil
+}
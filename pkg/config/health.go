@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+)
+
+var (
+	invalidHealthPortError = errors.New("invalid health port")
+)
+
+// HealthConfig is the configuration for the readiness endpoint
+type HealthConfig struct {
+	// Enabled is a flag that enables the readiness endpoint
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Port is the port that the readiness endpoint will listen on, defaults to 8081
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Path is the path of the readiness endpoint, defaults to /ready
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// validateHealth validates the HealthConfig struct and sets the default values
+func (h *HealthConfig) validateHealth() error {
+	if !h.Enabled {
+		return nil
+	}
+	if h.Port == 0 {
+		slog.Debug("No port defined for readiness endpoint, using default port 8081")
+		h.Port = 8081
+	}
+	if h.Port < 0 || h.Port > 65535 {
+		return invalidHealthPortError
+	}
+	if len(h.Path) == 0 {
+		h.Path = "/ready"
+	}
+	if h.Path[0] != '/' {
+		h.Path = "/" + h.Path
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	invalidHTTPConnectionLimitError = errors.New("http connection limits must not be negative")
+	invalidHTTPDurationError        = errors.New("http timeouts and durations must not be negative")
+)
+
+// HTTPClientConfig is the configuration for the connection pool that is used to send requests
+type HTTPClientConfig struct {
+	// MaxIdleConns is the maximum number of idle connections across all hosts, defaults to 100
+	MaxIdleConns int `yaml:"max-idle-conns,omitempty" json:"max-idle-conns,omitempty"`
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept for each host, defaults to 10
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host,omitempty" json:"max-idle-conns-per-host,omitempty"`
+
+	// MaxConnsPerHost is the maximum number of connections for each host, defaults to 0 which means no limit
+	MaxConnsPerHost int `yaml:"max-conns-per-host,omitempty" json:"max-conns-per-host,omitempty"`
+
+	// IdleConnTimeout is the time an idle connection is kept in the pool, defaults to 90 seconds
+	IdleConnTimeout time.Duration `yaml:"idle-conn-timeout,omitempty" json:"idle-conn-timeout,omitempty"`
+
+	// DialTimeout is the timeout of establishing a connection, defaults to 30 seconds
+	DialTimeout time.Duration `yaml:"dial-timeout,omitempty" json:"dial-timeout,omitempty"`
+
+	// KeepAlive is the interval of TCP keep-alive probes, defaults to 30 seconds
+	KeepAlive time.Duration `yaml:"keep-alive,omitempty" json:"keep-alive,omitempty"`
+
+	// TLSHandshakeTimeout is the timeout of the TLS handshake, defaults to 10 seconds
+	TLSHandshakeTimeout time.Duration `yaml:"tls-handshake-timeout,omitempty" json:"tls-handshake-timeout,omitempty"`
+
+	// DisableHTTP2 is the flag that disables HTTP/2, HTTP/2 is attempted by default
+	DisableHTTP2 *bool `yaml:"disable-http2,omitempty" json:"disable-http2,omitempty"`
+
+	// DisableKeepAlives is the flag that disables connection reuse
+	DisableKeepAlives *bool `yaml:"disable-keep-alives,omitempty" json:"disable-keep-alives,omitempty"`
+
+	// DNSCacheTTL is the duration that resolved addresses are cached, defaults to 0 which disables caching
+	DNSCacheTTL time.Duration `yaml:"dns-cache-ttl,omitempty" json:"dns-cache-ttl,omitempty"`
+}
+
+// inherit fills the fields that are not defined with the values of the parent configuration
+func (h *HTTPClientConfig) inherit(parent *HTTPClientConfig) {
+	if h.MaxIdleConns == 0 {
+		h.MaxIdleConns = parent.MaxIdleConns
+	}
+	if h.MaxIdleConnsPerHost == 0 {
+		h.MaxIdleConnsPerHost = parent.MaxIdleConnsPerHost
+	}
+	if h.MaxConnsPerHost == 0 {
+		h.MaxConnsPerHost = parent.MaxConnsPerHost
+	}
+	if h.IdleConnTimeout == 0 {
+		h.IdleConnTimeout = parent.IdleConnTimeout
+	}
+	if h.DialTimeout == 0 {
+		h.DialTimeout = parent.DialTimeout
+	}
+	if h.KeepAlive == 0 {
+		h.KeepAlive = parent.KeepAlive
+	}
+	if h.TLSHandshakeTimeout == 0 {
+		h.TLSHandshakeTimeout = parent.TLSHandshakeTimeout
+	}
+	if h.DNSCacheTTL == 0 {
+		h.DNSCacheTTL = parent.DNSCacheTTL
+	}
+	if h.DisableHTTP2 == nil {
+		h.DisableHTTP2 = parent.DisableHTTP2
+	}
+	if h.DisableKeepAlives == nil {
+		h.DisableKeepAlives = parent.DisableKeepAlives
+	}
+}
+
+// HTTP2Disabled reports whether HTTP/2 is disabled
+func (h *HTTPClientConfig) HTTP2Disabled() bool {
+	return h.DisableHTTP2 != nil && *h.DisableHTTP2
+}
+
+// KeepAlivesDisabled reports whether connection reuse is disabled
+func (h *HTTPClientConfig) KeepAlivesDisabled() bool {
+	return h.DisableKeepAlives != nil && *h.DisableKeepAlives
+}
+
+// validateHTTPClient validates the HTTPClientConfig struct and sets the default values
+func (h *HTTPClientConfig) validateHTTPClient() error {
+	if h.MaxIdleConns < 0 || h.MaxIdleConnsPerHost < 0 || h.MaxConnsPerHost < 0 {
+		return invalidHTTPConnectionLimitError
+	}
+	if h.IdleConnTimeout < 0 || h.DialTimeout < 0 || h.KeepAlive < 0 ||
+		h.TLSHandshakeTimeout < 0 || h.DNSCacheTTL < 0 {
+		return invalidHTTPDurationError
+	}
+	if h.MaxIdleConns == 0 {
+		h.MaxIdleConns = 100
+	}
+	if h.MaxIdleConnsPerHost == 0 {
+		h.MaxIdleConnsPerHost = 10
+	}
+	if h.IdleConnTimeout == 0 {
+		h.IdleConnTimeout = 90 * time.Second
+	}
+	if h.DialTimeout == 0 {
+		h.DialTimeout = 30 * time.Second
+	}
+	if h.KeepAlive == 0 {
+		h.KeepAlive = 30 * time.Second
+	}
+	if h.TLSHandshakeTimeout == 0 {
+		h.TLSHandshakeTimeout = 10 * time.Second
+	}
+	return nil
+}
+
+// applyHTTPClient resolves the connection pool configuration of a route from its own and the global configuration.
+// It is left nil when neither is defined, so that the route uses the settings of the Go default transport
+func (route *RouteConfig) applyHTTPClient(global *HTTPClientConfig) error {
+	switch {
+	case route.HTTP == nil && global != nil:
+		// each route gets its own copy, so that changing the configuration of one route does not change the others
+		copied := *global
+		route.HTTP = &copied
+		return nil
+	case route.HTTP == nil:
+		return nil
+	case global != nil:
+		route.HTTP.inherit(global)
+	}
+	return route.HTTP.validateHTTPClient()
+}
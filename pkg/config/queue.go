@@ -12,6 +12,8 @@ var (
 	queueNameNotDefinedError       = errors.New("queue name not defined")
 	queueProviderNotDefinedError   = errors.New("queue provider not defined")
 	queueProviderDoesNotExistError = errors.New("queue provider does not exist in providers list")
+	invalidMessageFormatError      = errors.New("invalid queue message format")
+	batchRouteFormatError          = errors.New("route batch without body requires json message format")
 
 	maxRetriesNotDefinedError = errors.New("max retries not defined")
 	intervalNotDefinedError   = errors.New("interval not defined")
@@ -22,12 +24,29 @@ var (
 	bodyNotDefinedError                  = errors.New("when using graphql type, body must be defined")
 	invalidBodyForGraphQLError           = errors.New("when using graphql type, body must contain query or mutation")
 	bodyNotContainsStringForGraphQLError = errors.New("when using graphql type, body must contain string for query or mutation")
+	captureNameDuplicatedError           = errors.New("capture name must be unique within a queue")
 
 	databaseRouteNameNotDefinedError              = errors.New("database route name not defined")
 	databaseRouteProviderNotDefinedError          = errors.New("database route provider not defined")
 	databaseRouteProviderDoesNotExistError        = errors.New("database route provider does not exist in databases list")
-	databaseRouteTableOrCollectionNotDefinedError = errors.New("database route table or collection not defined")
+	databaseRouteTableOrCollectionNotDefinedError = errors.New("database route table, collection, index, redis config or statements not defined")
 	dataBaseRouteMappingNotDefinedError           = errors.New("database route mapping not defined")
+	invalidDatabaseRouteModeError                 = errors.New("invalid database route mode")
+	databaseRouteKeysNotDefinedError              = errors.New("database route keys must be defined for upsert, update and delete modes")
+	databaseRouteKeyNotMappedError                = errors.New("database route key must be defined in the mapping")
+	invalidDatabaseRouteOnErrorError              = errors.New("invalid database route on-error behaviour")
+	databaseRouteModeNotSupportedError            = errors.New("database route mode not supported by the database type")
+	databaseRouteIndexNotDefinedError             = errors.New("database route index must be defined for elasticsearch")
+	databaseRouteRedisNotDefinedError             = errors.New("database route redis config must be defined for redis")
+	invalidRedisCommandError                      = errors.New("invalid database route redis command")
+	redisKeyNotDefinedError                       = errors.New("database route redis key not defined")
+	invalidRedisTTLError                          = errors.New("database route redis ttl and max-length must not be negative")
+	databaseRouteSchemaNotSupportedError          = errors.New("database route schema is only supported for postgresql")
+	databaseRouteSchemaColumnsNotDefinedError     = errors.New("database route schema columns not defined")
+	databaseRouteStatementsNotSupportedError      = errors.New("database route statements are only supported for postgresql")
+	databaseStatementTableNotDefinedError         = errors.New("database route statement table not defined")
+	databaseRouteStatementNotSupportedError       = errors.New("database route statement is only supported for postgresql, mysql and sqlite")
+	databaseRouteStatementConflictError           = errors.New("database route statement can not be combined with mapping, mode or statements")
 )
 
 // QueueConfig is the main configuration information needed to consume a queue
@@ -38,6 +57,12 @@ type QueueConfig struct {
 	// Provider is the provider that will be used to consume the queue
 	Provider string `yaml:"provider" json:"provider"`
 
+	// Format is the format of the messages of the queue (json, xml, csv, form, text, avro or protobuf), defaults to "json"
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+
+	// Schema is the configuration of the schemas that avro and protobuf messages are decoded with
+	Schema *SchemaConfig `yaml:"schema,omitempty" json:"schema,omitempty"`
+
 	// Retry is the retry configuration for the queue
 	Retry *RetryConfig `yaml:"retry,omitempty" json:"retry,omitempty"`
 
@@ -46,6 +71,15 @@ type QueueConfig struct {
 
 	// DatabaseRoutes is the list of databases that will be used to store the messages
 	DatabaseRoutes []*DatabaseRouteConfig `yaml:"database-routes,omitempty" json:"database-routes,omitempty"`
+
+	// RateLimit is the configuration for limiting the rate of requests of all routes of the queue
+	RateLimit *RateLimitConfig `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+
+	// MaxInFlight is the maximum number of concurrent requests of all routes of the queue, defaults to 0 which means no limit
+	MaxInFlight int `yaml:"max-in-flight,omitempty" json:"max-in-flight,omitempty"`
+
+	// Dedup is the configuration for skipping the messages that are already processed
+	Dedup *DedupConfig `yaml:"dedup,omitempty" json:"dedup,omitempty"`
 }
 
 // RetryConfig is the main configuration information needed to retry a message
@@ -91,6 +125,36 @@ type RouteConfig struct {
 
 	// Timeout is the timeout of the request, defaults to 10 seconds
 	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Signing is the configuration for signing the request
+	Signing *SigningConfig `yaml:"signing,omitempty" json:"signing,omitempty"`
+
+	// TLS is the configuration for the TLS connections of the route
+	TLS *TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+	// Proxy is the URL of the proxy that the requests will be sent through
+	Proxy string `yaml:"proxy,omitempty" json:"proxy,omitempty"`
+
+	// HTTP is the configuration for the connection pool of the route, overrides the global configuration
+	HTTP *HTTPClientConfig `yaml:"http,omitempty" json:"http,omitempty"`
+
+	// Capture is the name that the response of the route is stored under, so that later routes can use it in templates
+	Capture string `yaml:"capture,omitempty" json:"capture,omitempty"`
+
+	// Expect is the conditions that the response must meet for the route to succeed
+	Expect *ExpectConfig `yaml:"expect,omitempty" json:"expect,omitempty"`
+
+	// CircuitBreaker is the configuration for the circuit breaker of the route
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuit-breaker,omitempty" json:"circuit-breaker,omitempty"`
+
+	// RateLimit is the configuration for limiting the rate of requests of the route
+	RateLimit *RateLimitConfig `yaml:"rate-limit,omitempty" json:"rate-limit,omitempty"`
+
+	// MaxInFlight is the maximum number of concurrent requests of the route, defaults to 0 which means no limit
+	MaxInFlight int `yaml:"max-in-flight,omitempty" json:"max-in-flight,omitempty"`
+
+	// Batch sends the bodies of multiple messages together as a JSON array
+	Batch *BatchConfig `yaml:"batch,omitempty" json:"batch,omitempty"`
 }
 
 // DatabaseRouteConfig is the main configuration information needed to store a message in a database
@@ -107,8 +171,89 @@ type DatabaseRouteConfig struct {
 	// Collection is the name of the collection in a NoSQL database
 	Collection string `yaml:"collection,omitempty" json:"collection,omitempty"`
 
+	// Index is the name of the index in a search cluster, which can contain placeholders such as {{type}} or {{date:2006.01.02}}
+	Index string `yaml:"index,omitempty" json:"index,omitempty"`
+
+	// IDField is the message key whose value is used as the document ID, documents get a generated ID if it is not defined
+	IDField string `yaml:"id-field,omitempty" json:"id-field,omitempty"`
+
 	// Mapping is the mapping of the message to the database
 	Mapping map[string]string `yaml:"mapping" json:"mapping"`
+
+	// Mode is the operation performed with the data, which is insert, upsert, update or delete
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Keys are the message keys whose mapped columns identify the row to upsert, update or delete
+	Keys []string `yaml:"keys,omitempty" json:"keys,omitempty"`
+
+	// OnError is the behaviour when the write fails, which is retry, dead-letter or ignore
+	OnError string `yaml:"on-error,omitempty" json:"on-error,omitempty"`
+
+	// Batch writes the data of multiple messages in a single insert
+	Batch *BatchConfig `yaml:"batch,omitempty" json:"batch,omitempty"`
+
+	// Redis is the command and key that are used to write the data into Redis
+	Redis *RedisRouteConfig `yaml:"redis,omitempty" json:"redis,omitempty"`
+
+	// Schema is the declared schema of the table, which is created or altered at startup if migrate is enabled
+	Schema *TableSchemaConfig `yaml:"schema,omitempty" json:"schema,omitempty"`
+
+	// Statements are written in a single transaction instead of the table and mapping of the route,
+	// so that the data of a message is written into multiple tables or not at all
+	Statements []*DatabaseStatementConfig `yaml:"statements,omitempty" json:"statements,omitempty"`
+
+	// StrictMapping drops the fields of the message that are not mapped instead of writing them as they are
+	StrictMapping bool `yaml:"strict-mapping,omitempty" json:"strict-mapping,omitempty"`
+
+	// Statement is a raw SQL statement with named placeholders such as :id, which are bound to the fields
+	// of the message. It is written instead of the table and mapping of the route
+	Statement string `yaml:"statement,omitempty" json:"statement,omitempty"`
+}
+
+// DatabaseStatementConfig is a statement of a database route that writes the data of a message into a table
+type DatabaseStatementConfig struct {
+	// Table is the name of the table
+	Table string `yaml:"table" json:"table"`
+
+	// Mapping is the mapping of the message, or of an element of the for-each array, to the columns of the table
+	Mapping map[string]string `yaml:"mapping" json:"mapping"`
+
+	// Mode is the operation performed with the data, which is insert, upsert, update or delete
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// Keys are the keys whose mapped columns identify the row to upsert, update or delete
+	Keys []string `yaml:"keys,omitempty" json:"keys,omitempty"`
+
+	// ForEach is the message key of an array, a row is written for each of its elements
+	ForEach string `yaml:"for-each,omitempty" json:"for-each,omitempty"`
+}
+
+// TableSchemaConfig is the declared schema of the table of a database route
+type TableSchemaConfig struct {
+	// Columns are the SQL types of the columns by column name, such as "bigint" or "jsonb not null"
+	Columns map[string]string `yaml:"columns" json:"columns"`
+
+	// Migrate creates the table if it does not exist and adds the declared columns it does not have,
+	// columns are never changed or dropped
+	Migrate bool `yaml:"migrate,omitempty" json:"migrate,omitempty"`
+}
+
+// RedisRouteConfig is the configuration of the command that writes the data of a database route into Redis
+type RedisRouteConfig struct {
+	// Command is the command that is used to write the data, which is set, hset, lpush, xadd or incrby
+	Command string `yaml:"command" json:"command"`
+
+	// Key is the key that is written, which can contain placeholders such as user:{{id}} or {{date:2006-01-02}}
+	Key string `yaml:"key" json:"key"`
+
+	// TTL is the expiration that is set on the key after each write, defaults to 0 which means the key does not expire
+	TTL time.Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// MaxLength is the maximum length of the list or stream, older entries are trimmed, defaults to 0 which means no limit
+	MaxLength int64 `yaml:"max-length,omitempty" json:"max-length,omitempty"`
+
+	// IncrementField is the message key whose value is added to the counter by incrby, defaults to incrementing by 1
+	IncrementField string `yaml:"increment-field,omitempty" json:"increment-field,omitempty"`
 }
 
 func (queue *QueueConfig) validateQueue(providers []*ProviderConfig, databases []*DatabaseConfig) error {
@@ -129,6 +274,20 @@ func (queue *QueueConfig) validateQueue(providers []*ProviderConfig, databases [
 		return queueProviderDoesNotExistError
 	}
 
+	if queue.Format != "" &&
+		queue.Format != common.MessageFormatJSON &&
+		queue.Format != common.MessageFormatXML &&
+		queue.Format != common.MessageFormatCSV &&
+		queue.Format != common.MessageFormatForm &&
+		queue.Format != common.MessageFormatText &&
+		queue.Format != common.MessageFormatAvro &&
+		queue.Format != common.MessageFormatProtobuf {
+		return invalidMessageFormatError
+	}
+	if err := validateSchema(queue.Schema, queue.Format); err != nil {
+		return err
+	}
+
 	if queue.Retry != nil && queue.Retry.Enabled {
 		if queue.Retry.MaxRetries == 0 {
 			return maxRetriesNotDefinedError
@@ -151,79 +310,281 @@ func (queue *QueueConfig) validateQueue(providers []*ProviderConfig, databases [
 		}
 	}
 
+	if queue.RateLimit != nil {
+		if err := queue.RateLimit.validateRateLimit(); err != nil {
+			return err
+		}
+	}
+	if err := validateMaxInFlight(queue.MaxInFlight); err != nil {
+		return err
+	}
+	if queue.Dedup != nil {
+		if err := queue.Dedup.validateDedup(databases); err != nil {
+			return err
+		}
+	}
+
 	if len(queue.Routes) > 0 {
+		captures := make(map[string]bool)
 		for _, route := range queue.Routes {
-			if len(route.Name) == 0 {
-				return routeNameNotDefinedError
+			if err := route.validateRoute(); err != nil {
+				return err
 			}
-			if len(route.URL) == 0 {
-				return urlNotDefinedError
+			// batches are sent as a JSON array of the bodies, so messages that are forwarded as they are must be JSON
+			if route.Batch != nil && len(route.Body) == 0 && queue.Format != "" && queue.Format != common.MessageFormatJSON {
+				return batchRouteFormatError
 			}
-			if route.Method == "" {
-				slog.Debug("Route method not defined, using default method POST", "route", route.Name)
-				route.Method = "POST"
-			}
-			if route.Type == "" {
-				slog.Debug("Route type not defined, using default type REST", "route", route.Name)
-				route.Type = common.RouteTypeREST
-			}
-			if route.Type == common.RouteTypeGraphQL {
-				if len(route.Body) == 0 {
-					return bodyNotDefinedError
-				}
-				v1, ok := route.Body["query"]
-				v2, ok2 := route.Body["mutation"]
-				if !ok && !ok2 {
-					return invalidBodyForGraphQLError
-				}
-				if ok || ok2 {
-					if ok {
-						_, ok = v1.(string)
-					}
-					if ok2 {
-						_, ok2 = v2.(string)
-					}
-					if !ok && !ok2 {
-						return bodyNotContainsStringForGraphQLError
-					}
-				}
-				if route.Method != "POST" {
-					slog.Debug("GraphQL route method is not POST, setting it to POST", "route", route.Name)
-					route.Method = "POST"
+			if len(route.Capture) > 0 {
+				if captures[route.Capture] {
+					return captureNameDuplicatedError
 				}
-			}
-			if route.Timeout == 0 {
-				slog.Debug("Route timeout not defined, using default timeout 10 seconds", "route", route.Name)
-				route.Timeout = 10 * time.Second
+				captures[route.Capture] = true
 			}
 		}
 	}
 
 	if len(queue.DatabaseRoutes) > 0 {
 		for _, databaseRoute := range queue.DatabaseRoutes {
-			if len(databaseRoute.Name) == 0 {
-				return databaseRouteNameNotDefinedError
+			if err := databaseRoute.validateDatabaseRoute(databases); err != nil {
+				return err
 			}
-			if len(databaseRoute.Provider) == 0 {
-				return databaseRouteProviderNotDefinedError
+		}
+	}
+	return nil
+}
+
+// validateDatabaseRoute validates the DatabaseRouteConfig struct and sets the default values
+func (databaseRoute *DatabaseRouteConfig) validateDatabaseRoute(databases []*DatabaseConfig) error {
+	if len(databaseRoute.Name) == 0 {
+		return databaseRouteNameNotDefinedError
+	}
+	if len(databaseRoute.Provider) == 0 {
+		return databaseRouteProviderNotDefinedError
+	}
+	var provider *DatabaseConfig
+	for _, database := range databases {
+		if database.Name == databaseRoute.Provider {
+			provider = database
+			break
+		}
+	}
+	if provider == nil {
+		return databaseRouteProviderDoesNotExistError
+	}
+	if len(databaseRoute.Statement) > 0 {
+		if err := databaseRoute.validateDatabaseRouteStatement(provider); err != nil {
+			return err
+		}
+	}
+	if len(databaseRoute.Table) == 0 && len(databaseRoute.Collection) == 0 && len(databaseRoute.Index) == 0 &&
+		databaseRoute.Redis == nil && len(databaseRoute.Statements) == 0 && len(databaseRoute.Statement) == 0 {
+		return databaseRouteTableOrCollectionNotDefinedError
+	}
+	if provider.Type == common.DatabaseTypeElasticsearch && len(databaseRoute.Index) == 0 {
+		return databaseRouteIndexNotDefinedError
+	}
+	if provider.Type == common.DatabaseTypeRedis {
+		if databaseRoute.Redis == nil {
+			return databaseRouteRedisNotDefinedError
+		}
+		if err := databaseRoute.Redis.validateRedisRoute(); err != nil {
+			return err
+		}
+	}
+	if databaseRoute.Schema != nil {
+		if provider.Type != common.DatabaseTypePostgresql {
+			return databaseRouteSchemaNotSupportedError
+		}
+		if len(databaseRoute.Schema.Columns) == 0 {
+			return databaseRouteSchemaColumnsNotDefinedError
+		}
+	}
+	if len(databaseRoute.Statements) > 0 {
+		if provider.Type != common.DatabaseTypePostgresql {
+			return databaseRouteStatementsNotSupportedError
+		}
+		for _, stmt := range databaseRoute.Statements {
+			if err := stmt.validateDatabaseStatement(); err != nil {
+				return err
 			}
-			databaseExists := false
-			for _, database := range databases {
-				if database.Name == databaseRoute.Provider {
-					databaseExists = true
-					break
-				}
+		}
+	}
+	// incrby counters only need the key, routes with statements map the data in each statement and
+	// raw statements bind their placeholders, the other routes write the mapped data
+	isCounter := databaseRoute.Redis != nil && databaseRoute.Redis.Command == common.RedisCommandIncrBy
+	if len(databaseRoute.Mapping) == 0 && !isCounter && len(databaseRoute.Statements) == 0 && len(databaseRoute.Statement) == 0 {
+		return dataBaseRouteMappingNotDefinedError
+	}
+	if err := validateDatabaseMode(&databaseRoute.Mode, databaseRoute.Keys, databaseRoute.Mapping); err != nil {
+		return err
+	}
+	if (provider.Type == common.DatabaseTypeClickHouse ||
+		provider.Type == common.DatabaseTypeElasticsearch ||
+		provider.Type == common.DatabaseTypeRedis) &&
+		databaseRoute.Mode != common.DatabaseRouteModeInsert {
+		return databaseRouteModeNotSupportedError
+	}
+	if databaseRoute.OnError == "" {
+		databaseRoute.OnError = common.DatabaseRouteOnErrorRetry
+	}
+	if databaseRoute.OnError != common.DatabaseRouteOnErrorRetry &&
+		databaseRoute.OnError != common.DatabaseRouteOnErrorDeadLetter &&
+		databaseRoute.OnError != common.DatabaseRouteOnErrorIgnore {
+		return invalidDatabaseRouteOnErrorError
+	}
+	if databaseRoute.Batch != nil {
+		if err := databaseRoute.Batch.validateBatch(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDatabaseRouteStatement validates the raw SQL statement of a database route, the statement itself
+// is validated by the database at startup
+func (databaseRoute *DatabaseRouteConfig) validateDatabaseRouteStatement(provider *DatabaseConfig) error {
+	switch provider.Type {
+	case common.DatabaseTypePostgresql, common.DatabaseTypeMySQL, common.DatabaseTypeSQLite:
+	default:
+		return databaseRouteStatementNotSupportedError
+	}
+	if len(databaseRoute.Mapping) > 0 || len(databaseRoute.Statements) > 0 ||
+		(databaseRoute.Mode != "" && databaseRoute.Mode != common.DatabaseRouteModeInsert) {
+		return databaseRouteStatementConflictError
+	}
+	return nil
+}
+
+// validateDatabaseStatement validates the DatabaseStatementConfig struct and sets the default values
+func (stmt *DatabaseStatementConfig) validateDatabaseStatement() error {
+	if len(stmt.Table) == 0 {
+		return databaseStatementTableNotDefinedError
+	}
+	if len(stmt.Mapping) == 0 {
+		return dataBaseRouteMappingNotDefinedError
+	}
+	return validateDatabaseMode(&stmt.Mode, stmt.Keys, stmt.Mapping)
+}
+
+// validateDatabaseMode validates the mode and its keys, and defaults the mode to insert
+func validateDatabaseMode(mode *string, keys []string, mapping map[string]string) error {
+	if *mode == "" {
+		*mode = common.DatabaseRouteModeInsert
+	}
+	switch *mode {
+	case common.DatabaseRouteModeInsert:
+	case common.DatabaseRouteModeUpsert, common.DatabaseRouteModeUpdate, common.DatabaseRouteModeDelete:
+		if len(keys) == 0 {
+			return databaseRouteKeysNotDefinedError
+		}
+		for _, key := range keys {
+			if _, ok := mapping[key]; !ok {
+				return databaseRouteKeyNotMappedError
 			}
-			if !databaseExists {
-				return databaseRouteProviderDoesNotExistError
+		}
+	default:
+		return invalidDatabaseRouteModeError
+	}
+	return nil
+}
+
+// validateRedisRoute validates the RedisRouteConfig struct
+func (r *RedisRouteConfig) validateRedisRoute() error {
+	switch r.Command {
+	case common.RedisCommandSet, common.RedisCommandHSet, common.RedisCommandLPush,
+		common.RedisCommandXAdd, common.RedisCommandIncrBy:
+	default:
+		return invalidRedisCommandError
+	}
+	if len(r.Key) == 0 {
+		return redisKeyNotDefinedError
+	}
+	if r.TTL < 0 || r.MaxLength < 0 {
+		return invalidRedisTTLError
+	}
+	return nil
+}
+
+// validateRoute validates the RouteConfig struct and sets the default values
+func (route *RouteConfig) validateRoute() error {
+	if len(route.Name) == 0 {
+		return routeNameNotDefinedError
+	}
+	if len(route.URL) == 0 {
+		return urlNotDefinedError
+	}
+	if route.Method == "" {
+		slog.Debug("Route method not defined, using default method POST", "route", route.Name)
+		route.Method = "POST"
+	}
+	if route.Type == "" {
+		slog.Debug("Route type not defined, using default type REST", "route", route.Name)
+		route.Type = common.RouteTypeREST
+	}
+	if route.Type == common.RouteTypeGraphQL {
+		if len(route.Body) == 0 {
+			return bodyNotDefinedError
+		}
+		v1, ok := route.Body["query"]
+		v2, ok2 := route.Body["mutation"]
+		if !ok && !ok2 {
+			return invalidBodyForGraphQLError
+		}
+		if ok || ok2 {
+			if ok {
+				_, ok = v1.(string)
 			}
-			if len(databaseRoute.Table) == 0 && len(databaseRoute.Collection) == 0 {
-				return databaseRouteTableOrCollectionNotDefinedError
+			if ok2 {
+				_, ok2 = v2.(string)
 			}
-			if len(databaseRoute.Mapping) == 0 {
-				return dataBaseRouteMappingNotDefinedError
+			if !ok && !ok2 {
+				return bodyNotContainsStringForGraphQLError
 			}
 		}
+		if route.Method != "POST" {
+			slog.Debug("GraphQL route method is not POST, setting it to POST", "route", route.Name)
+			route.Method = "POST"
+		}
+	}
+	if route.Timeout == 0 {
+		slog.Debug("Route timeout not defined, using default timeout 10 seconds", "route", route.Name)
+		route.Timeout = 10 * time.Second
+	}
+	if route.Expect != nil {
+		if err := route.Expect.validateExpect(); err != nil {
+			return err
+		}
+	}
+	if route.Signing != nil {
+		if err := route.Signing.validateSigning(); err != nil {
+			return err
+		}
+	}
+	if route.TLS != nil {
+		if err := route.TLS.validateTLS(); err != nil {
+			return err
+		}
+	}
+	if len(route.Proxy) > 0 {
+		if err := validateProxy(route.Proxy); err != nil {
+			return err
+		}
+	}
+	if route.CircuitBreaker != nil {
+		if err := route.CircuitBreaker.validateCircuitBreaker(); err != nil {
+			return err
+		}
+	}
+	if route.RateLimit != nil {
+		if err := route.RateLimit.validateRateLimit(); err != nil {
+			return err
+		}
+	}
+	if err := validateMaxInFlight(route.MaxInFlight); err != nil {
+		return err
+	}
+	if route.Batch != nil {
+		return route.validateRouteBatch()
 	}
 	return nil
 }
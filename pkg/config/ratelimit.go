@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	rateLimitRateNotDefinedError = errors.New("rate limit rate must be greater than 0")
+	invalidRateLimitBurstError   = errors.New("rate limit burst must not be negative")
+	invalidMaxInFlightError      = errors.New("max in flight must not be negative")
+)
+
+// RateLimitConfig is the configuration for the token bucket that limits the rate of outgoing requests
+type RateLimitConfig struct {
+	// Rate is the number of requests allowed per second
+	Rate float64 `yaml:"rate" json:"rate"`
+
+	// Burst is the maximum number of requests that can be sent at once, defaults to the rate rounded up
+	Burst int `yaml:"burst,omitempty" json:"burst,omitempty"`
+}
+
+// validateRateLimit validates the RateLimitConfig struct and sets the default values
+func (r *RateLimitConfig) validateRateLimit() error {
+	if r.Rate <= 0 {
+		return rateLimitRateNotDefinedError
+	}
+	if r.Burst < 0 {
+		return invalidRateLimitBurstError
+	}
+	if r.Burst == 0 {
+		r.Burst = int(math.Ceil(r.Rate))
+	}
+	return nil
+}
+
+// validateMaxInFlight validates the maximum number of concurrent requests
+func validateMaxInFlight(maxInFlight int) error {
+	if maxInFlight < 0 {
+		return invalidMaxInFlightError
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+var (
+	schemaNotDefinedError             = errors.New("queue schema must be defined for avro and protobuf formats")
+	schemaNotSupportedError           = errors.New("queue schema is only supported for avro and protobuf formats")
+	schemaSourceError                 = errors.New("queue schema must define either a registry or a file")
+	schemaFileDoesNotExistError       = errors.New("queue schema file does not exist")
+	schemaRegistryURLNotDefinedError  = errors.New("queue schema registry url not defined")
+	invalidSchemaRegistryURLError     = errors.New("invalid queue schema registry url")
+	invalidSchemaRegistryTimeoutError = errors.New("queue schema registry timeout must not be negative")
+)
+
+// SchemaConfig is the configuration of the schemas that avro and protobuf messages are decoded with
+type SchemaConfig struct {
+	// Registry is the Confluent compatible schema registry that the schemas are fetched from by the schema ID of each message
+	Registry *SchemaRegistryConfig `yaml:"registry,omitempty" json:"registry,omitempty"`
+
+	// File is the path of the local .avsc or .proto file of the schema, used instead of a registry
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// ImportPaths are the directories that the imports of a .proto file are searched in, the directory of the file is always searched
+	ImportPaths []string `yaml:"import-paths,omitempty" json:"import-paths,omitempty"`
+
+	// Message is the full name of the protobuf message, defaults to the message in the wire header or the first message of the file
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Framed is the flag that indicates that messages decoded with a local file start with the Confluent wire header
+	Framed bool `yaml:"framed,omitempty" json:"framed,omitempty"`
+}
+
+// SchemaRegistryConfig is the configuration of a Confluent compatible schema registry
+type SchemaRegistryConfig struct {
+	// URL is the base URL of the schema registry
+	URL string `yaml:"url" json:"url"`
+
+	// Username is the username of the basic authentication
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+
+	// Password is the password of the basic authentication
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+
+	// Timeout is the timeout of the requests to the schema registry, defaults to 10 seconds
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// TLS is the configuration for the TLS connection to the schema registry
+	TLS *TLSConfig `yaml:"tls,omitempty" json:"tls,omitempty"`
+}
+
+// validateSchema validates the schema configuration of a queue with the given message format and sets the default values
+func validateSchema(schema *SchemaConfig, format string) error {
+	if format != common.MessageFormatAvro && format != common.MessageFormatProtobuf {
+		if schema != nil {
+			return schemaNotSupportedError
+		}
+		return nil
+	}
+	if schema == nil {
+		return schemaNotDefinedError
+	}
+	if (schema.Registry == nil) == (len(schema.File) == 0) {
+		return schemaSourceError
+	}
+	if len(schema.File) > 0 {
+		if _, err := os.Stat(schema.File); err != nil {
+			return schemaFileDoesNotExistError
+		}
+		return nil
+	}
+
+	registry := schema.Registry
+	if len(registry.URL) == 0 {
+		return schemaRegistryURLNotDefinedError
+	}
+	u, err := url.Parse(registry.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return invalidSchemaRegistryURLError
+	}
+	if registry.Timeout < 0 {
+		return invalidSchemaRegistryTimeoutError
+	}
+	if registry.Timeout == 0 {
+		registry.Timeout = common.DefaultSchemaRegistryTimeout
+	}
+	if registry.TLS != nil {
+		return registry.TLS.validateTLS()
+	}
+	return nil
+}
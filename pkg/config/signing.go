@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+var (
+	invalidSigningSchemeError       = errors.New("invalid signing scheme")
+	invalidSigningAlgorithmError    = errors.New("invalid signing algorithm")
+	signingSecretNotDefinedError    = errors.New("signing secret not defined")
+	awsSigningConfigNotDefinedError = errors.New("aws signing config not defined")
+	awsAccessKeyNotDefinedError     = errors.New("aws access key id not defined")
+	awsSecretKeyNotDefinedError     = errors.New("aws secret access key not defined")
+	awsRegionNotDefinedError        = errors.New("aws region not defined")
+	awsServiceNotDefinedError       = errors.New("aws service not defined")
+)
+
+// SigningConfig is the configuration for signing the outgoing requests of a route
+type SigningConfig struct {
+	// Scheme is the signing scheme, either "digest", "stripe" or "aws-sigv4", defaults to "digest"
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+
+	// Algorithm is the hash algorithm of the HMAC, either "sha256" or "sha512", defaults to "sha256"
+	Algorithm string `yaml:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// Secret is the shared secret that is used to compute the HMAC
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty"`
+
+	// Header is the name of the header that carries the signature, defaults to "X-Konsume-Signature"
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// TimestampHeader is the name of the header that carries the timestamp in digest scheme, defaults to "X-Konsume-Timestamp"
+	TimestampHeader string `yaml:"timestamp-header,omitempty" json:"timestamp-header,omitempty"`
+
+	// AWS is the configuration for the aws-sigv4 scheme
+	AWS *AWSSigningConfig `yaml:"aws,omitempty" json:"aws,omitempty"`
+}
+
+// AWSSigningConfig is the configuration needed to sign requests with AWS Signature Version 4
+type AWSSigningConfig struct {
+	// AccessKeyID is the access key id of the credentials
+	AccessKeyID string `yaml:"access-key-id" json:"access-key-id"`
+
+	// SecretAccessKey is the secret access key of the credentials
+	SecretAccessKey string `yaml:"secret-access-key" json:"secret-access-key"`
+
+	// SessionToken is the optional session token of temporary credentials
+	SessionToken string `yaml:"session-token,omitempty" json:"session-token,omitempty"`
+
+	// Region is the region of the endpoint, such as "us-east-1"
+	Region string `yaml:"region" json:"region"`
+
+	// Service is the name of the service, such as "execute-api" or "s3"
+	Service string `yaml:"service" json:"service"`
+}
+
+// validateSigning validates the SigningConfig struct and sets the default values
+func (s *SigningConfig) validateSigning() error {
+	if s.Scheme == "" {
+		slog.Debug("Signing scheme not defined, using default scheme digest")
+		s.Scheme = common.SigningSchemeDigest
+	}
+	if s.Header == "" {
+		s.Header = "X-Konsume-Signature"
+	}
+	if s.TimestampHeader == "" {
+		s.TimestampHeader = "X-Konsume-Timestamp"
+	}
+
+	switch s.Scheme {
+	case common.SigningSchemeDigest, common.SigningSchemeStripe:
+		if s.Algorithm == "" {
+			s.Algorithm = common.SigningAlgorithmSHA256
+		}
+		if s.Algorithm != common.SigningAlgorithmSHA256 && s.Algorithm != common.SigningAlgorithmSHA512 {
+			return invalidSigningAlgorithmError
+		}
+		if len(s.Secret) == 0 {
+			return signingSecretNotDefinedError
+		}
+	case common.SigningSchemeAWSSigV4:
+		if s.AWS == nil {
+			return awsSigningConfigNotDefinedError
+		}
+		return s.AWS.validateAWSSigning()
+	default:
+		return invalidSigningSchemeError
+	}
+
+	return nil
+}
+
+// validateAWSSigning validates the AWSSigningConfig struct
+func (a *AWSSigningConfig) validateAWSSigning() error {
+	if len(a.AccessKeyID) == 0 {
+		return awsAccessKeyNotDefinedError
+	}
+	if len(a.SecretAccessKey) == 0 {
+		return awsSecretKeyNotDefinedError
+	}
+	if len(a.Region) == 0 {
+		return awsRegionNotDefinedError
+	}
+	if len(a.Service) == 0 {
+		return awsServiceNotDefinedError
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+)
+
+var (
+	invalidTLSVersionError      = errors.New("invalid tls min version")
+	tlsCertOrKeyNotDefinedError = errors.New("tls cert-file and key-file must be defined together")
+	tlsFileDoesNotExistError    = errors.New("tls file does not exist")
+	invalidProxyURLError        = errors.New("invalid proxy url")
+)
+
+var supportedTLSVersions = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// TLSConfig is the configuration for the TLS connections of a route or database
+type TLSConfig struct {
+	// CAFile is the path of the PEM encoded CA bundle that is used to verify the server certificate
+	CAFile string `yaml:"ca-file,omitempty" json:"ca-file,omitempty"`
+
+	// CertFile is the path of the PEM encoded client certificate
+	CertFile string `yaml:"cert-file,omitempty" json:"cert-file,omitempty"`
+
+	// KeyFile is the path of the PEM encoded client private key
+	KeyFile string `yaml:"key-file,omitempty" json:"key-file,omitempty"`
+
+	// ServerName is the name that is used to verify the server certificate, defaults to the host of the URL
+	ServerName string `yaml:"server-name,omitempty" json:"server-name,omitempty"`
+
+	// MinVersion is the minimum TLS version, either "1.0", "1.1", "1.2" or "1.3", defaults to "1.2"
+	MinVersion string `yaml:"min-version,omitempty" json:"min-version,omitempty"`
+
+	// InsecureSkipVerify is the flag that disables the verification of the server certificate
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify,omitempty" json:"insecure-skip-verify,omitempty"`
+}
+
+// validateTLS validates the TLSConfig struct and sets the default values
+func (t *TLSConfig) validateTLS() error {
+	if t.MinVersion == "" {
+		t.MinVersion = "1.2"
+	}
+	validVersion := false
+	for _, v := range supportedTLSVersions {
+		if t.MinVersion == v {
+			validVersion = true
+			break
+		}
+	}
+	if !validVersion {
+		return invalidTLSVersionError
+	}
+	if (len(t.CertFile) == 0) != (len(t.KeyFile) == 0) {
+		return tlsCertOrKeyNotDefinedError
+	}
+	for _, file := range []string{t.CAFile, t.CertFile, t.KeyFile} {
+		if len(file) == 0 {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return tlsFileDoesNotExistError
+		}
+	}
+	return nil
+}
+
+// validateProxy validates the proxy url of a route
+func validateProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return invalidProxyURLError
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+)
+
+type ClickHousePlugin struct {
+	conn driver.Conn
+	cfg  *config.ClickHouseConfig
+	pool *config.PoolConfig
+
+	mu          sync.Mutex
+	columnTypes map[string]map[string]string
+}
+
+// Configure stores the insert and connection pool configuration that is used when connecting and writing
+func (p *ClickHousePlugin) Configure(cfg config.DatabaseConfig) error {
+	p.cfg = cfg.ClickHouseConfig
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect establishes a connection to the ClickHouse database
+func (p *ClickHousePlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to ClickHouse database")
+	options, err := clickhouse.ParseDSN(connectionString)
+	if err != nil {
+		return err
+	}
+	if dbName != "" {
+		options.Auth.Database = dbName
+	}
+	if p.pool != nil {
+		if p.pool.MaxOpenConns > 0 {
+			options.MaxOpenConns = p.pool.MaxOpenConns
+		}
+		if p.pool.MaxIdleConns > 0 {
+			options.MaxIdleConns = p.pool.MaxIdleConns
+		}
+		if p.pool.ConnMaxLifetime > 0 {
+			options.ConnMaxLifetime = p.pool.ConnMaxLifetime
+		}
+	}
+	p.conn, err = clickhouse.Open(options)
+	if err != nil {
+		return err
+	}
+	if err = p.conn.Ping(context.Background()); err != nil {
+		return err
+	}
+	p.columnTypes = make(map[string]map[string]string)
+	slog.Info("Connected to the ClickHouse database")
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (p *ClickHousePlugin) Ping(ctx context.Context) error {
+	return p.conn.Ping(ctx)
+}
+
+// Insert stores data into the ClickHouse database
+func (p *ClickHousePlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	return p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+}
+
+// InsertMany stores the data of multiple messages in native batches, one for each set of mapped columns
+func (p *ClickHousePlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	types, err := p.tableColumns(dbRouteConfig.Table)
+	if err != nil {
+		return err
+	}
+	groups := batchGroups(data, dbRouteConfig)
+	if len(groups) == 0 {
+		return fmt.Errorf("no mapped columns found for table %s", dbRouteConfig.Table)
+	}
+	for _, group := range groups {
+		for _, column := range group.columns {
+			if _, ok := types[column]; !ok {
+				return fmt.Errorf("column %s does not exist in table %s", column, dbRouteConfig.Table)
+			}
+		}
+	}
+	keys := make(map[string]string, len(dbRouteConfig.Mapping))
+	for key, column := range dbRouteConfig.Mapping {
+		keys[column] = key
+	}
+
+	writeCtx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	ctx := clickhouse.Context(writeCtx, clickhouse.WithSettings(p.settings()))
+	for _, group := range groups {
+		if err = p.sendBatch(ctx, dbRouteConfig.Table, group, keys, types); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Wrote batch into the database", "table", dbRouteConfig.Table, "rows", len(data))
+	return nil
+}
+
+// Close terminates the database connection
+func (p *ClickHousePlugin) Close() error {
+	if p.conn != nil {
+		slog.Info("Closing the ClickHouse database connection")
+		return p.conn.Close()
+	}
+	return nil
+}
+
+// settings returns the query settings of the inserts
+func (p *ClickHousePlugin) settings() clickhouse.Settings {
+	settings := clickhouse.Settings{}
+	if p.cfg != nil && p.cfg.AsyncInsert {
+		settings["async_insert"] = 1
+		settings["wait_for_async_insert"] = 1
+		if p.cfg.WaitForAsyncInsert != nil && !*p.cfg.WaitForAsyncInsert {
+			settings["wait_for_async_insert"] = 0
+		}
+	}
+	return settings
+}
+
+// tableColumns returns the column types of the table, which are looked up once and cached
+func (p *ClickHousePlugin) tableColumns(table string) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if types, ok := p.columnTypes[table]; ok {
+		return types, nil
+	}
+	query := "SELECT name, type FROM system.columns WHERE database = currentDatabase() AND table = ?"
+	args := []interface{}{table}
+	if database, name, ok := strings.Cut(table, "."); ok {
+		query = "SELECT name, type FROM system.columns WHERE database = ? AND table = ?"
+		args = []interface{}{database, name}
+	}
+	rows, err := p.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up columns of table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, chType string
+		if err = rows.Scan(&name, &chType); err != nil {
+			return nil, err
+		}
+		types[name] = chType
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+	p.columnTypes[table] = types
+	return types, nil
+}
+
+// sendBatch writes the rows of a group in a single native batch
+func (p *ClickHousePlugin) sendBatch(ctx context.Context, table string, group rowGroup, keys, types map[string]string) error {
+	batch, err := p.conn.PrepareBatch(ctx, insertQuery(table, group.columns))
+	if err != nil {
+		return fmt.Errorf("error preparing batch: %w", err)
+	}
+	defer batch.Abort()
+
+	for _, row := range group.rows {
+		values := make([]interface{}, len(group.columns))
+		for i, column := range group.columns {
+			values[i], err = convertValue(row[keys[column]], types[column])
+			if err != nil {
+				return fmt.Errorf("error converting %s to %s: %w", keys[column], types[column], err)
+			}
+		}
+		if err = batch.Append(values...); err != nil {
+			return fmt.Errorf("error appending row: %w", err)
+		}
+	}
+	if err = batch.Send(); err != nil {
+		return fmt.Errorf("error sending batch: %w", err)
+	}
+	return nil
+}
+
+// rowGroup is the rows of a batch that have values for the same columns
+type rowGroup struct {
+	columns []string
+	rows    []map[string]interface{}
+}
+
+// batchGroups groups the rows by their sorted mapped columns, so that columns without a value in a row are left
+// out of its insert and get the default of the column instead of the zero value of its type
+func batchGroups(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) []rowGroup {
+	var groups []rowGroup
+	indexes := make(map[string]int)
+	for _, row := range data {
+		var columns []string
+		for key := range row {
+			column, ok := dbRouteConfig.Mapping[key]
+			if !ok {
+				slog.Warn("No mapping found for", "key", key)
+				continue
+			}
+			columns = append(columns, column)
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		sort.Strings(columns)
+
+		signature := strings.Join(columns, ",")
+		index, ok := indexes[signature]
+		if !ok {
+			index = len(groups)
+			indexes[signature] = index
+			groups = append(groups, rowGroup{columns: columns})
+		}
+		groups[index].rows = append(groups[index].rows, row)
+	}
+	return groups
+}
+
+// insertQuery returns the insert query of the batch with quoted identifiers
+func insertQuery(table string, columns []string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(part)
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(column)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s)", strings.Join(parts, "."), strings.Join(quoted, ", "))
+}
+
+func quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func init() {
+	database.Register(common.DatabaseTypeClickHouse, func() database.Database {
+		return &ClickHousePlugin{}
+	})
+}
@@ -0,0 +1,343 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+	"github.com/shopspring/decimal"
+)
+
+// timeLayouts are the layouts that string values are parsed with for Date and DateTime columns
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+var (
+	scanTypesMu sync.Mutex
+	scanTypes   = make(map[string]reflect.Type)
+)
+
+// scanType returns the Go type the driver expects for the ClickHouse type
+func scanType(chType string) (reflect.Type, error) {
+	scanTypesMu.Lock()
+	defer scanTypesMu.Unlock()
+
+	if t, ok := scanTypes[chType]; ok {
+		return t, nil
+	}
+	col, err := column.Type(chType).Column("", time.UTC)
+	if err != nil {
+		return nil, err
+	}
+	scanTypes[chType] = col.ScanType()
+	return scanTypes[chType], nil
+}
+
+// convertValue converts a value decoded from a JSON message into the Go type the driver expects for the ClickHouse type
+func convertValue(value interface{}, chType string) (interface{}, error) {
+	chType = strings.TrimSpace(chType)
+	if inner, ok := unwrapType(chType, "LowCardinality"); ok {
+		return convertValue(value, inner)
+	}
+	if inner, ok := unwrapType(chType, "Nullable"); ok {
+		if value == nil {
+			return nil, nil
+		}
+		return convertValue(value, inner)
+	}
+	if inner, ok := unwrapType(chType, "Array"); ok {
+		return convertArray(value, chType, inner)
+	}
+	if inner, ok := unwrapType(chType, "Map"); ok {
+		return convertMap(value, chType, inner)
+	}
+
+	base := chType
+	if i := strings.Index(base, "("); i >= 0 {
+		base = base[:i]
+	}
+	switch base {
+	case "Int8":
+		n, err := toInt(value, 8)
+		return int8(n), err
+	case "Int16":
+		n, err := toInt(value, 16)
+		return int16(n), err
+	case "Int32":
+		n, err := toInt(value, 32)
+		return int32(n), err
+	case "Int64":
+		return toInt(value, 64)
+	case "UInt8":
+		n, err := toUint(value, 8)
+		return uint8(n), err
+	case "UInt16":
+		n, err := toUint(value, 16)
+		return uint16(n), err
+	case "UInt32":
+		n, err := toUint(value, 32)
+		return uint32(n), err
+	case "UInt64":
+		return toUint(value, 64)
+	case "Int128", "Int256", "UInt128", "UInt256":
+		return toBigInt(value)
+	case "Float32":
+		f, err := toFloat(value)
+		return float32(f), err
+	case "Float64":
+		return toFloat(value)
+	case "Bool", "Boolean":
+		return toBool(value)
+	case "Decimal", "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		return toDecimal(value)
+	case "Date", "Date32", "DateTime", "DateTime64":
+		return toTime(value)
+	case "String", "FixedString", "Enum8", "Enum16", "UUID", "IPv4", "IPv6", "JSON", "Object":
+		return toString(value)
+	default:
+		return value, nil
+	}
+}
+
+// convertArray converts a JSON array into a slice of the Go type of the elements
+func convertArray(value interface{}, chType, inner string) (interface{}, error) {
+	t, err := scanType(chType)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		if value != nil {
+			return nil, fmt.Errorf("expected an array for %s, got %T", chType, value)
+		}
+		items = nil
+	}
+	slice := reflect.MakeSlice(t, 0, len(items))
+	for _, item := range items {
+		converted, err := convertValue(item, inner)
+		if err != nil {
+			return nil, err
+		}
+		slice = reflect.Append(slice, reflectValue(converted, t.Elem()))
+	}
+	return slice.Interface(), nil
+}
+
+// convertMap converts a JSON object into a map of the Go types of the keys and values
+func convertMap(value interface{}, chType, inner string) (interface{}, error) {
+	t, err := scanType(chType)
+	if err != nil {
+		return nil, err
+	}
+	keyType, valueType, ok := splitTypeArguments(inner)
+	if !ok {
+		return nil, fmt.Errorf("invalid map type %s", chType)
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok && value != nil {
+		return nil, fmt.Errorf("expected an object for %s, got %T", chType, value)
+	}
+	m := reflect.MakeMapWithSize(t, len(object))
+	for k, v := range object {
+		key, err := convertValue(k, keyType)
+		if err != nil {
+			return nil, err
+		}
+		converted, err := convertValue(v, valueType)
+		if err != nil {
+			return nil, err
+		}
+		m.SetMapIndex(reflectValue(key, t.Key()), reflectValue(converted, t.Elem()))
+	}
+	return m.Interface(), nil
+}
+
+// reflectValue returns the converted value as a reflect.Value of type t, taking the address for pointer types of nullable elements
+func reflectValue(converted interface{}, t reflect.Type) reflect.Value {
+	if converted == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(converted)
+	if t.Kind() == reflect.Ptr && v.Type() == t.Elem() {
+		p := reflect.New(t.Elem())
+		p.Elem().Set(v)
+		return p
+	}
+	return v
+}
+
+// unwrapType returns the argument of a type like Nullable(String) if the type has the given name
+func unwrapType(chType, name string) (string, bool) {
+	if strings.HasPrefix(chType, name+"(") && strings.HasSuffix(chType, ")") {
+		return chType[len(name)+1 : len(chType)-1], true
+	}
+	return "", false
+}
+
+// splitTypeArguments splits the two top level arguments of a type like Map(String, Array(Int32))
+func splitTypeArguments(arguments string) (string, string, bool) {
+	depth := 0
+	for i, c := range arguments {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				return strings.TrimSpace(arguments[:i]), strings.TrimSpace(arguments[i+1:]), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func toInt(value interface{}, bits int) (int64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return strconv.ParseInt(strconv.FormatFloat(v, 'f', -1, 64), 10, bits)
+	case string:
+		return strconv.ParseInt(v, 10, bits)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("can not convert %T to an integer", value)
+	}
+}
+
+func toUint(value interface{}, bits int) (uint64, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return strconv.ParseUint(strconv.FormatFloat(v, 'f', -1, 64), 10, bits)
+	case string:
+		return strconv.ParseUint(v, 10, bits)
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("can not convert %T to an unsigned integer", value)
+	}
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	var s string
+	switch v := value.(type) {
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		s = v
+	case nil:
+		s = "0"
+	default:
+		return nil, fmt.Errorf("can not convert %T to a big integer", value)
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an integer", s)
+	}
+	return n, nil
+}
+
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("can not convert %T to a float", value)
+	}
+}
+
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		return v != 0, nil
+	case string:
+		return strconv.ParseBool(v)
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("can not convert %T to a bool", value)
+	}
+}
+
+func toDecimal(value interface{}) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	case string:
+		return decimal.NewFromString(v)
+	case nil:
+		return decimal.Zero, nil
+	default:
+		return decimal.Zero, fmt.Errorf("can not convert %T to a decimal", value)
+	}
+}
+
+// toTime converts a string in one of the time layouts or a number of unix seconds into a time
+func toTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("can not parse %q as a time", v)
+	case float64:
+		seconds, fraction := math.Modf(v)
+		return time.Unix(int64(seconds), int64(fraction*1e9)).UTC(), nil
+	case nil:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, fmt.Errorf("can not convert %T to a time", value)
+	}
+}
+
+// toString converts a value into a string, encoding objects and arrays as JSON
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	}
+}
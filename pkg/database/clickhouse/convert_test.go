@@ -0,0 +1,110 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/column"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		chType string
+		value  interface{}
+	}{
+		{name: "int32", chType: "Int32", value: float64(42)},
+		{name: "uint8 from string", chType: "UInt8", value: "7"},
+		{name: "int128", chType: "Int128", value: "170141183460469231731687303715884105727"},
+		{name: "float32", chType: "Float32", value: 1.5},
+		{name: "bool", chType: "Bool", value: true},
+		{name: "string from object", chType: "String", value: map[string]interface{}{"a": 1.0}},
+		{name: "low cardinality", chType: "LowCardinality(String)", value: "web"},
+		{name: "nullable nil", chType: "Nullable(Int64)", value: nil},
+		{name: "nullable value", chType: "Nullable(Int64)", value: float64(3)},
+		{name: "uuid", chType: "UUID", value: "0b4c4e1e-4b5a-4b8e-9c3b-1d3e5f7a9b1c"},
+		{name: "enum", chType: "Enum8('click' = 1, 'view' = 2)", value: "view"},
+		{name: "date", chType: "Date", value: "2024-01-02"},
+		{name: "datetime rfc3339", chType: "DateTime", value: "2024-01-02T15:04:05Z"},
+		{name: "datetime with zone", chType: "DateTime('Europe/Istanbul')", value: "2024-01-02 15:04:05"},
+		{name: "datetime64 unix", chType: "DateTime64(3)", value: 1704207845.123},
+		{name: "decimal", chType: "Decimal(10, 2)", value: "12.34"},
+		{name: "array", chType: "Array(Int32)", value: []interface{}{1.0, 2.0}},
+		{name: "array of nullable", chType: "Array(Nullable(String))", value: []interface{}{"a", nil}},
+		{name: "nested array", chType: "Array(Array(UInt16))", value: []interface{}{[]interface{}{1.0}, []interface{}{}}},
+		{name: "map", chType: "Map(String, UInt64)", value: map[string]interface{}{"a": 1.0, "b": 2.0}},
+		{name: "map of arrays", chType: "Map(String, Array(String))", value: map[string]interface{}{"tags": []interface{}{"x"}}},
+		{name: "missing value", chType: "DateTime", value: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			col, err := column.Type(tc.chType).Column("c", time.UTC)
+			if err != nil {
+				t.Fatalf("Column() error = %v", err)
+			}
+			converted, err := convertValue(tc.value, tc.chType)
+			if err != nil {
+				t.Fatalf("convertValue() error = %v", err)
+			}
+			if err = col.AppendRow(converted); err != nil {
+				t.Errorf("AppendRow(%T) error = %v", converted, err)
+			}
+		})
+	}
+}
+
+func TestConvertValue_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		chType string
+		value  interface{}
+	}{
+		{name: "fraction as integer", chType: "Int32", value: 1.5},
+		{name: "overflow", chType: "Int8", value: float64(300)},
+		{name: "invalid time", chType: "DateTime", value: "yesterday"},
+		{name: "object as array", chType: "Array(String)", value: map[string]interface{}{}},
+		{name: "invalid decimal", chType: "Decimal(10, 2)", value: "abc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := convertValue(tc.value, tc.chType); err == nil {
+				t.Errorf("expected an error converting %v to %s", tc.value, tc.chType)
+			}
+		})
+	}
+}
+
+func TestConvertValue_Time(t *testing.T) {
+	converted, err := convertValue(float64(1704207845), "Nullable(DateTime)")
+	if err != nil {
+		t.Fatalf("convertValue() error = %v", err)
+	}
+	expected := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	if got, ok := converted.(time.Time); !ok || !got.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, converted)
+	}
+}
+
+func TestInsertQuery(t *testing.T) {
+	cfg := config.DatabaseRouteConfig{Mapping: map[string]string{"id": "event_id", "type": "event_type"}}
+	data := []map[string]interface{}{{"id": 1.0, "type": "view", "unmapped": true}, {"type": "click"}, {"id": 2.0, "type": "view"}}
+
+	groups := batchGroups(data, cfg)
+	if len(groups) != 2 || len(groups[0].rows) != 2 || len(groups[1].rows) != 1 {
+		t.Fatalf("expected the rows to be grouped by their mapped columns, got %v", groups)
+	}
+
+	expected := []string{
+		"INSERT INTO `analytics`.`events` (`event_id`, `event_type`)",
+		"INSERT INTO `analytics`.`events` (`event_type`)",
+	}
+	for i, group := range groups {
+		if query := insertQuery("analytics.events", group.columns); query != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], query)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package database
 
-import "github.com/bugrakocabay/konsume/pkg/config"
+import (
+	"fmt"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
 
 // Database is an interface that defines the methods that a database should implement
 type Database interface {
@@ -8,3 +12,38 @@ type Database interface {
 	Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error
 	Close() error
 }
+
+// BatchInserter is the interface that databases implement when they can insert the data of multiple messages at once
+type BatchInserter interface {
+	InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error
+}
+
+// Configurable is the interface that databases implement when they need the database configuration before connecting
+type Configurable interface {
+	Configure(cfg config.DatabaseConfig) error
+}
+
+// RoutePreparer is the interface that databases implement to check or create the tables of their routes at startup
+type RoutePreparer interface {
+	PrepareRoute(dbRouteConfig config.DatabaseRouteConfig) error
+}
+
+// BatchError is returned by InsertMany when only some of the rows could not be written,
+// Errors holds the error of each row in the order of the data and is nil for the rows that were written
+type BatchError struct {
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e.Errors {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d rows failed: %v", failed, len(e.Errors), first)
+}
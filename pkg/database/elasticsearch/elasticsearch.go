@@ -0,0 +1,298 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/requester"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+const (
+	// bulkRetries is the amount of times the documents rejected with 429 Too Many Requests are sent again
+	bulkRetries = 3
+
+	// bulkRetryInterval is the interval before the rejected documents are sent again, multiplied by the attempt
+	bulkRetryInterval = 500 * time.Millisecond
+
+	requestTimeout = 30 * time.Second
+)
+
+type ElasticsearchPlugin struct {
+	client *http.Client
+	url    string
+	cfg    *config.ElasticsearchConfig
+	pool   *config.PoolConfig
+}
+
+// bulkResponse is the part of the bulk API response that reports the result of each document
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkResponseItem `json:"items"`
+}
+
+type bulkResponseItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// Configure stores the authentication and TLS configuration that is used when connecting
+func (p *ElasticsearchPlugin) Configure(cfg config.DatabaseConfig) error {
+	p.cfg = cfg.ElasticsearchConfig
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect creates the HTTP client of the cluster and checks that the cluster is reachable
+func (p *ElasticsearchPlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to Elasticsearch cluster")
+	p.url = strings.TrimSuffix(connectionString, "/")
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.cfg != nil && p.cfg.TLS != nil {
+		tlsConfig, err := requester.NewTLSConfig(p.cfg.TLS)
+		if err != nil {
+			return err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	timeout := requestTimeout
+	if p.pool != nil {
+		if p.pool.MaxOpenConns > 0 {
+			transport.MaxConnsPerHost = p.pool.MaxOpenConns
+		}
+		if p.pool.MaxIdleConns > 0 {
+			transport.MaxIdleConnsPerHost = p.pool.MaxIdleConns
+		}
+		if p.pool.ConnMaxIdleTime > 0 {
+			transport.IdleConnTimeout = p.pool.ConnMaxIdleTime
+		}
+		if p.pool.StatementTimeout > 0 {
+			timeout = p.pool.StatementTimeout
+		}
+	}
+	p.client = &http.Client{Transport: transport, Timeout: timeout}
+
+	if err := p.Ping(context.Background()); err != nil {
+		return err
+	}
+	slog.Info("Connected to the Elasticsearch cluster")
+	return nil
+}
+
+// Ping checks that the cluster can be reached
+func (p *ElasticsearchPlugin) Ping(ctx context.Context) error {
+	resp, err := p.do(ctx, http.MethodGet, "/", "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code from cluster: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Insert indexes the data as a document
+func (p *ElasticsearchPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	err := p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	var batchErr *database.BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr.Errors[0]
+	}
+	return err
+}
+
+// InsertMany indexes the data of multiple messages with the bulk API, the documents rejected because
+// the cluster is overloaded are sent again and the other rejected documents are reported in a database.BatchError
+func (p *ElasticsearchPlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	errs := make([]error, len(data))
+	lines := make([][]byte, len(data))
+	pending := make([]int, 0, len(data))
+	for i, row := range data {
+		lines[i], errs[i] = bulkLines(row, dbRouteConfig)
+		if errs[i] == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * bulkRetryInterval)
+		}
+		statuses, err := p.bulk(pending, lines)
+		if err != nil {
+			return err
+		}
+		var rejected []int
+		for j, i := range pending {
+			item := statuses[j]
+			switch {
+			case item.Error == nil && item.Status < 300:
+				errs[i] = nil
+			case item.Status == http.StatusTooManyRequests && attempt < bulkRetries:
+				rejected = append(rejected, i)
+			default:
+				errs[i] = itemError(item)
+			}
+		}
+		pending = rejected
+	}
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	slog.Info("Indexed documents", "route", dbRouteConfig.Name, "documents", len(data)-failed, "failed", failed)
+	if failed > 0 {
+		return &database.BatchError{Errors: errs}
+	}
+	return nil
+}
+
+// Close releases the idle connections of the client
+func (p *ElasticsearchPlugin) Close() error {
+	if p.client != nil {
+		slog.Info("Closing the Elasticsearch connections")
+		p.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// bulk sends the lines of the pending documents to the bulk API and returns the result of each document in the same order
+func (p *ElasticsearchPlugin) bulk(pending []int, lines [][]byte) ([]bulkResponseItem, error) {
+	var body bytes.Buffer
+	for _, i := range pending {
+		body.Write(lines[i])
+	}
+	resp, err := p.do(context.Background(), http.MethodPost, "/_bulk", "application/x-ndjson", &body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("bulk request failed with status code %d: %s", resp.StatusCode, respBody)
+	}
+	var result bulkResponse
+	if err = json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("error decoding bulk response: %w", err)
+	}
+	if len(result.Items) != len(pending) {
+		return nil, fmt.Errorf("bulk response contains %d items for %d documents", len(result.Items), len(pending))
+	}
+	statuses := make([]bulkResponseItem, len(pending))
+	for i, item := range result.Items {
+		for _, status := range item {
+			statuses[i] = status
+		}
+	}
+	return statuses, nil
+}
+
+// do sends a request to the cluster with the configured authentication
+func (p *ElasticsearchPlugin) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if p.cfg != nil {
+		if p.cfg.APIKey != "" {
+			req.Header.Set("Authorization", "ApiKey "+p.cfg.APIKey)
+		} else if p.cfg.Username != "" {
+			req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+		}
+	}
+	return p.client.Do(req)
+}
+
+// bulkLines returns the action and document lines of the bulk request for a message
+func bulkLines(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) ([]byte, error) {
+	index, err := util.ProcessStringTemplate(dbRouteConfig.Index, data)
+	if err != nil {
+		return nil, fmt.Errorf("error building index name: %w", err)
+	}
+	action := map[string]interface{}{"_index": index}
+	if dbRouteConfig.IDField != "" {
+		id, err := documentID(data, dbRouteConfig.IDField)
+		if err != nil {
+			return nil, err
+		}
+		action["_id"] = id
+	}
+
+	document := make(map[string]interface{}, len(dbRouteConfig.Mapping))
+	for key, field := range dbRouteConfig.Mapping {
+		value, ok := data[key]
+		if !ok {
+			slog.Warn("No value found for", "key", key)
+			continue
+		}
+		document[field] = value
+	}
+
+	actionLine, err := json.Marshal(map[string]interface{}{"index": action})
+	if err != nil {
+		return nil, err
+	}
+	documentLine, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding document: %w", err)
+	}
+	lines := append(actionLine, '\n')
+	lines = append(lines, documentLine...)
+	return append(lines, '\n'), nil
+}
+
+// documentID returns the value of the ID field as a string
+func documentID(data map[string]interface{}, idField string) (string, error) {
+	value, ok := util.LookupPath(data, idField)
+	if !ok {
+		return "", fmt.Errorf("id field %s not found in message", idField)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("id field %s must be a string or a number", idField)
+	}
+}
+
+// itemError returns the error of a document rejected by the bulk API
+func itemError(item bulkResponseItem) error {
+	if item.Error != nil {
+		return fmt.Errorf("document rejected with status %d: %s: %s", item.Status, item.Error.Type, item.Error.Reason)
+	}
+	return fmt.Errorf("document rejected with status %d", item.Status)
+}
+
+func init() {
+	database.Register(common.DatabaseTypeElasticsearch, func() database.Database {
+		return &ElasticsearchPlugin{}
+	})
+}
@@ -0,0 +1,141 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+)
+
+// testCluster is a stand-in for the bulk API that rejects documents with the status of their "status" field,
+// documents with the status 429 are only rejected the first time they are sent
+type testCluster struct {
+	mu        sync.Mutex
+	auth      []string
+	actions   []map[string]interface{}
+	documents []map[string]interface{}
+	throttled map[interface{}]bool
+}
+
+func (c *testCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.auth = append(c.auth, r.Header.Get("Authorization"))
+	if r.URL.Path == "/" {
+		w.Write([]byte(`{"version":{"number":"8.13.0"}}`))
+		return
+	}
+
+	var items []map[string]interface{}
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action map[string]map[string]interface{}
+		json.Unmarshal(scanner.Bytes(), &action)
+		scanner.Scan()
+		var document map[string]interface{}
+		json.Unmarshal(scanner.Bytes(), &document)
+
+		status := 201
+		if s, ok := document["status"].(float64); ok {
+			status = int(s)
+		}
+		if status == http.StatusTooManyRequests && c.throttled[document["id"]] {
+			status = 201
+		}
+		item := map[string]interface{}{"status": status}
+		switch status {
+		case 201:
+			c.actions = append(c.actions, action["index"])
+			c.documents = append(c.documents, document)
+		case http.StatusTooManyRequests:
+			c.throttled[document["id"]] = true
+			item["error"] = map[string]string{"type": "es_rejected_execution_exception", "reason": "queue full"}
+		default:
+			item["error"] = map[string]string{"type": "mapper_parsing_exception", "reason": "failed to parse"}
+		}
+		items = append(items, map[string]interface{}{"index": item})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": true, "items": items})
+}
+
+func connectTestCluster(t *testing.T, cfg *config.ElasticsearchConfig) (*ElasticsearchPlugin, *testCluster) {
+	t.Helper()
+	cluster := &testCluster{throttled: make(map[interface{}]bool)}
+	server := httptest.NewServer(cluster)
+	t.Cleanup(server.Close)
+
+	p := &ElasticsearchPlugin{}
+	p.Configure(config.DatabaseConfig{ElasticsearchConfig: cfg})
+	if err := p.Connect(server.URL+"/", ""); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p, cluster
+}
+
+func TestElasticsearchPlugin_Insert(t *testing.T) {
+	p, cluster := connectTestCluster(t, &config.ElasticsearchConfig{APIKey: "secret"})
+	cfg := config.DatabaseRouteConfig{
+		Index:   "events-{{type}}",
+		IDField: "user.id",
+		Mapping: map[string]string{"type": "event_type", "user": "user"},
+	}
+
+	err := p.Insert(map[string]interface{}{"type": "click", "user": map[string]interface{}{"id": float64(42)}}, cfg)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	if cluster.auth[1] != "ApiKey secret" {
+		t.Errorf("expected the api key to be sent, got %s", cluster.auth[1])
+	}
+	if len(cluster.actions) != 1 || cluster.actions[0]["_index"] != "events-click" || cluster.actions[0]["_id"] != "42" {
+		t.Errorf("unexpected bulk actions %v", cluster.actions)
+	}
+	if cluster.documents[0]["event_type"] != "click" {
+		t.Errorf("unexpected document %v", cluster.documents[0])
+	}
+}
+
+func TestElasticsearchPlugin_InsertMany_PartialFailure(t *testing.T) {
+	p, cluster := connectTestCluster(t, &config.ElasticsearchConfig{Username: "elastic", Password: "changeme"})
+	cfg := config.DatabaseRouteConfig{
+		Index:   "events",
+		IDField: "id",
+		Mapping: map[string]string{"id": "id", "status": "status"},
+	}
+
+	err := p.InsertMany([]map[string]interface{}{
+		{"id": "a"},
+		{"id": "b", "status": float64(400)},
+		{"id": "c", "status": float64(429)},
+		{"status": float64(201)},
+	}, cfg)
+
+	var batchErr *database.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a batch error, got %v", err)
+	}
+	if batchErr.Errors[0] != nil || batchErr.Errors[2] != nil {
+		t.Errorf("expected the indexed and retried documents to succeed, got %v", batchErr.Errors)
+	}
+	if batchErr.Errors[1] == nil || !strings.Contains(batchErr.Errors[1].Error(), "mapper_parsing_exception") {
+		t.Errorf("expected the rejected document to fail, got %v", batchErr.Errors[1])
+	}
+	if batchErr.Errors[3] == nil {
+		t.Errorf("expected the document without an id to fail")
+	}
+	if len(cluster.documents) != 2 {
+		t.Errorf("expected 2 indexed documents, got %v", cluster.documents)
+	}
+	if !strings.HasPrefix(cluster.auth[1], "Basic ") {
+		t.Errorf("expected basic authentication, got %s", cluster.auth[1])
+	}
+}
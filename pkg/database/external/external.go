@@ -0,0 +1,376 @@
+package external
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	handshakeTimeout = 10 * time.Second
+	pingTimeout      = 5 * time.Second
+	stopTimeout      = 5 * time.Second
+
+	// insertTimeout is the timeout of an insert when the pool configuration has no statement timeout
+	insertTimeout = 30 * time.Second
+)
+
+var errNotRunning = errors.New("external plugin is not running")
+
+// ExternalPlugin is a database whose data is written by a plugin process, which konsume starts,
+// health checks and restarts, and talks to with gRPC over a local socket
+type ExternalPlugin struct {
+	name             string
+	cfg              *config.ExternalConfig
+	pool             *config.PoolConfig
+	connectionString string
+	dbName           string
+
+	mu       sync.Mutex
+	process  *process
+	restarts int
+	closed   bool
+	done     chan struct{}
+}
+
+// process is a running plugin process and the client of its socket
+type process struct {
+	cmd    *exec.Cmd
+	client *grpc.ClientConn
+	exited chan struct{}
+}
+
+// Configure stores the configuration of the plugin process that is started when connecting
+func (p *ExternalPlugin) Configure(cfg config.DatabaseConfig) error {
+	p.name = cfg.Name
+	p.cfg = cfg.ExternalConfig
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect starts the plugin process, connects it to its database and starts the health checks
+func (p *ExternalPlugin) Connect(connectionString, dbName string) error {
+	if p.cfg == nil {
+		return errors.New("external plugin is not configured")
+	}
+	slog.Info("Starting external plugin", "database", p.name, "command", p.cfg.Command)
+	p.connectionString = connectionString
+	p.dbName = dbName
+	proc, err := p.start()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.process = proc
+	p.done = make(chan struct{})
+	p.mu.Unlock()
+	go p.supervise(p.done)
+	slog.Info("Started external plugin", "database", p.name, "pid", proc.cmd.Process.Pid)
+	return nil
+}
+
+// Insert sends the data to the plugin process, failing if it does not answer within the statement timeout
+// of the pool configuration
+func (p *ExternalPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	proc := p.current()
+	if proc == nil {
+		return errNotRunning
+	}
+	req, err := toStruct(InsertArgs{Data: data, Route: dbRouteConfig})
+	if err != nil {
+		return fmt.Errorf("error encoding insert request: %w", err)
+	}
+	timeout := insertTimeout
+	if p.pool != nil && p.pool.StatementTimeout > 0 {
+		timeout = p.pool.StatementTimeout
+	}
+	return proc.call(methodInsert, req, timeout)
+}
+
+// Ping checks that the plugin process is running and answers, within the deadline of the context
+func (p *ExternalPlugin) Ping(ctx context.Context) error {
+	proc := p.current()
+	if proc == nil {
+		return errNotRunning
+	}
+	timeout := pingTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	return proc.call(methodPing, &emptypb.Empty{}, timeout)
+}
+
+// Close stops the health checks and the plugin process
+func (p *ExternalPlugin) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	proc := p.process
+	p.process = nil
+	if p.done != nil {
+		close(p.done)
+	}
+	p.mu.Unlock()
+
+	if proc == nil {
+		return nil
+	}
+	slog.Info("Stopping external plugin", "database", p.name)
+	err := proc.call(methodClose, &emptypb.Empty{}, stopTimeout)
+	proc.stop()
+	return err
+}
+
+func (p *ExternalPlugin) current() *process {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.process
+}
+
+// supervise restarts the plugin process when it exits or fails a health check, until it is closed
+// or the process has been restarted the maximum amount of times
+func (p *ExternalPlugin) supervise(done chan struct{}) {
+	ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		proc := p.current()
+		var exited chan struct{}
+		if proc != nil {
+			exited = proc.exited
+		}
+		select {
+		case <-done:
+			return
+		case <-exited:
+			slog.Warn("External plugin exited", "database", p.name)
+		case <-ticker.C:
+			if proc == nil {
+				break
+			}
+			err := proc.call(methodPing, &emptypb.Empty{}, pingTimeout)
+			if err == nil {
+				continue
+			}
+			slog.Warn("External plugin failed the health check", "database", p.name, "error", err)
+		}
+		if !p.restart(proc) {
+			return
+		}
+	}
+}
+
+// restart replaces the process with a new one and reports whether the supervision should continue
+func (p *ExternalPlugin) restart(proc *process) bool {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return false
+	}
+	p.process = nil
+	if p.restarts >= *p.cfg.MaxRestarts {
+		p.mu.Unlock()
+		slog.Error("External plugin reached the maximum amount of restarts", "database", p.name, "restarts", p.restarts)
+		if proc != nil {
+			proc.stop()
+		}
+		return false
+	}
+	p.restarts++
+	restarts := p.restarts
+	p.mu.Unlock()
+
+	slog.Warn("Restarting external plugin", "database", p.name, "restart", restarts)
+	if proc != nil {
+		proc.stop()
+	}
+	newProc, err := p.start()
+	if err != nil {
+		slog.Error("Failed to restart external plugin", "database", p.name, "error", err)
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		newProc.stop()
+		return false
+	}
+	p.process = newProc
+	return true
+}
+
+// start starts the plugin process, waits for its handshake and connects it to its database
+func (p *ExternalPlugin) start() (*process, error) {
+	cmd := exec.Command(p.cfg.Command, p.cfg.Args...)
+	cmd.Env = append(os.Environ(), environment(p.cfg.Env)...)
+	cmd.Stderr = os.Stderr
+	stdout, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = writer
+	if err = cmd.Start(); err != nil {
+		stdout.Close()
+		writer.Close()
+		return nil, fmt.Errorf("error starting external plugin: %w", err)
+	}
+	writer.Close()
+
+	proc := &process{cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(proc.exited)
+	}()
+
+	handshake := make(chan string, 1)
+	go readOutput(stdout, handshake)
+	var line string
+	select {
+	case line = <-handshake:
+	case <-proc.exited:
+		return nil, errors.New("external plugin exited before the handshake")
+	case <-time.After(handshakeTimeout):
+		proc.stop()
+		return nil, errors.New("timed out waiting for the handshake of the external plugin")
+	}
+
+	network, address, err := parseHandshake(line)
+	if err != nil {
+		proc.stop()
+		return nil, err
+	}
+	proc.client, err = grpc.NewClient("passthrough:///"+address,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		}),
+	)
+	if err != nil {
+		proc.stop()
+		return nil, fmt.Errorf("error connecting to external plugin: %w", err)
+	}
+
+	connectReq, err := toStruct(ConnectArgs{ConnectionString: p.connectionString, Database: p.dbName})
+	if err != nil {
+		proc.stop()
+		return nil, err
+	}
+	if err = proc.call(methodConnect, connectReq, handshakeTimeout); err != nil {
+		proc.stop()
+		return nil, fmt.Errorf("error connecting external plugin to its database: %w", err)
+	}
+	return proc, nil
+}
+
+// call calls a method of the plugin and fails if it does not return within the timeout or the process exits.
+// The error of the plugin is returned with its message only
+func (proc *process) call(method string, req proto.Message, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- proc.client.Invoke(ctx, method, req, &emptypb.Empty{})
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-proc.exited:
+		return errNotRunning
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s", method, timeout)
+	}
+	if s, ok := status.FromError(err); ok && err != nil {
+		return errors.New(s.Message())
+	}
+	return err
+}
+
+// stop closes the connection, which ends the plugin, and kills the process if it does not exit in time
+func (proc *process) stop() {
+	if proc.client != nil {
+		proc.client.Close()
+	}
+	select {
+	case <-proc.exited:
+	case <-time.After(stopTimeout):
+		proc.cmd.Process.Kill()
+		<-proc.exited
+	}
+}
+
+// readOutput sends the handshake line of the plugin and logs the rest of its stdout
+func readOutput(stdout io.ReadCloser, handshake chan<- string) {
+	defer stdout.Close()
+	scanner := bufio.NewScanner(stdout)
+	found := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !found && strings.HasPrefix(line, handshakePrefix+"|") {
+			found = true
+			handshake <- line
+			continue
+		}
+		slog.Info("External plugin output", "line", line)
+	}
+}
+
+// parseHandshake returns the network and address of a handshake line such as KONSUME_PLUGIN|1|unix|/tmp/plugin.sock
+func parseHandshake(line string) (string, string, error) {
+	parts := strings.SplitN(line, "|", 4)
+	if len(parts) != 4 {
+		return "", "", fmt.Errorf("invalid handshake from external plugin: %s", line)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version != ProtocolVersion {
+		return "", "", fmt.Errorf("unsupported external plugin protocol version %s, expected %d", parts[1], ProtocolVersion)
+	}
+	if parts[2] != "unix" && parts[2] != "tcp" {
+		return "", "", fmt.Errorf("unsupported external plugin network %s", parts[2])
+	}
+	return parts[2], parts[3], nil
+}
+
+// environment returns the variables in the KEY=value format sorted by key
+func environment(env map[string]string) []string {
+	vars := make([]string, 0, len(env))
+	for key, value := range env {
+		vars = append(vars, key+"="+value)
+	}
+	sort.Strings(vars)
+	return vars
+}
+
+func init() {
+	database.Register(common.DatabaseTypeExternal, func() database.Database {
+		return &ExternalPlugin{}
+	})
+}
@@ -0,0 +1,188 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+// testPluginEnv makes the test binary run as a plugin that appends the inserted data to the file of its connection string
+const testPluginEnv = "KONSUME_TEST_EXTERNAL_PLUGIN"
+
+type fileDatabase struct {
+	path string
+}
+
+func (d *fileDatabase) Connect(connectionString, dbName string) error {
+	d.path = connectionString
+	return nil
+}
+
+func (d *fileDatabase) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	if data["crash"] == true {
+		os.Exit(1)
+	}
+	if data["slow"] == true {
+		time.Sleep(time.Second)
+	}
+	if data["fail"] == true {
+		return errors.New("invalid row")
+	}
+	f, err := os.OpenFile(d.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data["table"] = dbRouteConfig.Table
+	return json.NewEncoder(f).Encode(data)
+}
+
+func (d *fileDatabase) Close() error { return nil }
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) == "1" {
+		if err := Serve(&fileDatabase{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func startTestPlugin(t *testing.T, maxRestarts int) (*ExternalPlugin, string) {
+	t.Helper()
+	p := &ExternalPlugin{}
+	p.Configure(config.DatabaseConfig{
+		Name: "test",
+		Pool: &config.PoolConfig{StatementTimeout: 500 * time.Millisecond},
+		ExternalConfig: &config.ExternalConfig{
+			Command:             os.Args[0],
+			Env:                 map[string]string{testPluginEnv: "1"},
+			HealthCheckInterval: 20 * time.Millisecond,
+			MaxRestarts:         &maxRestarts,
+		},
+	})
+	path := filepath.Join(t.TempDir(), "rows.json")
+	if err := p.Connect(path, ""); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p, path
+}
+
+func readRows(t *testing.T, path string) []string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+// waitForProcess waits until the plugin has been restarted the given amount of times and is running
+func waitForProcess(t *testing.T, p *ExternalPlugin, restarts int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		running := p.process != nil && p.restarts == restarts
+		p.mu.Unlock()
+		if running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("plugin was not restarted %d times", restarts)
+}
+
+func TestExternalPlugin_Insert(t *testing.T) {
+	p, path := startTestPlugin(t, 1)
+	route := config.DatabaseRouteConfig{Table: "users"}
+
+	if err := p.Insert(map[string]interface{}{"id": float64(1)}, route); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	err := p.Insert(map[string]interface{}{"fail": true}, route)
+	if err == nil || !strings.Contains(err.Error(), "invalid row") {
+		t.Errorf("expected the error of the plugin, got %v", err)
+	}
+
+	rows := readRows(t, path)
+	if len(rows) != 1 || rows[0] != `{"id":1,"table":"users"}` {
+		t.Errorf("unexpected rows %v", rows)
+	}
+}
+
+func TestExternalPlugin_InsertTimeout(t *testing.T) {
+	p, _ := startTestPlugin(t, 1)
+
+	err := p.Insert(map[string]interface{}{"slow": true}, config.DatabaseRouteConfig{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected the insert to time out, got %v", err)
+	}
+	if err = p.Ping(context.Background()); err != nil {
+		t.Errorf("expected the plugin to still answer after a timed out insert, got %v", err)
+	}
+}
+
+func TestExternalPlugin_RestartsAfterExit(t *testing.T) {
+	p, path := startTestPlugin(t, 1)
+
+	if err := p.Insert(map[string]interface{}{"crash": true}, config.DatabaseRouteConfig{}); err == nil {
+		t.Errorf("expected an error when the plugin exits")
+	}
+	waitForProcess(t, p, 1)
+
+	if err := p.Insert(map[string]interface{}{"id": float64(2)}, config.DatabaseRouteConfig{}); err != nil {
+		t.Fatalf("Insert() after restart error = %v", err)
+	}
+	if rows := readRows(t, path); len(rows) != 1 {
+		t.Errorf("expected 1 row after restart, got %v", rows)
+	}
+}
+
+func TestExternalPlugin_MaxRestarts(t *testing.T) {
+	p, _ := startTestPlugin(t, 0)
+
+	p.Insert(map[string]interface{}{"crash": true}, config.DatabaseRouteConfig{})
+	time.Sleep(100 * time.Millisecond)
+
+	err := p.Insert(map[string]interface{}{"id": float64(3)}, config.DatabaseRouteConfig{})
+	if !errors.Is(err, errNotRunning) {
+		t.Errorf("expected the plugin not to be restarted, got %v", err)
+	}
+}
+
+func TestParseHandshake(t *testing.T) {
+	tests := []struct {
+		line    string
+		network string
+		address string
+		valid   bool
+	}{
+		{line: "KONSUME_PLUGIN|1|unix|/tmp/plugin.sock", network: "unix", address: "/tmp/plugin.sock", valid: true},
+		{line: "KONSUME_PLUGIN|1|tcp|127.0.0.1:4000", network: "tcp", address: "127.0.0.1:4000", valid: true},
+		{line: "KONSUME_PLUGIN|2|unix|/tmp/plugin.sock"},
+		{line: "KONSUME_PLUGIN|1|udp|127.0.0.1:4000"},
+		{line: "KONSUME_PLUGIN|1"},
+	}
+
+	for _, tc := range tests {
+		network, address, err := parseHandshake(tc.line)
+		if tc.valid && (err != nil || network != tc.network || address != tc.address) {
+			t.Errorf("parseHandshake(%s) = %s, %s, %v", tc.line, network, address, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("parseHandshake(%s) expected an error", tc.line)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package external
+
+import (
+	"encoding/json"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ProtocolVersion is the version of the plugin protocol, which is part of the handshake
+	ProtocolVersion = 1
+
+	// handshakePrefix starts the line a plugin writes to its stdout once it is listening,
+	// such as KONSUME_PLUGIN|1|unix|/tmp/plugin.sock
+	handshakePrefix = "KONSUME_PLUGIN"
+
+	// serviceName is the full name of the gRPC service of plugin.proto
+	serviceName = "konsume.plugin.v1.Plugin"
+
+	methodConnect = "/" + serviceName + "/Connect"
+	methodInsert  = "/" + serviceName + "/Insert"
+	methodPing    = "/" + serviceName + "/Ping"
+	methodClose   = "/" + serviceName + "/Close"
+)
+
+// ConnectArgs are the fields of the request of Plugin.Connect
+type ConnectArgs struct {
+	ConnectionString string `json:"connection-string"`
+	Database         string `json:"database"`
+}
+
+// InsertArgs are the fields of the request of Plugin.Insert
+type InsertArgs struct {
+	Data  map[string]interface{}     `json:"data"`
+	Route config.DatabaseRouteConfig `json:"route"`
+}
+
+// toStruct encodes the arguments of a method as the google.protobuf.Struct of its request through their JSON form
+func toStruct(args interface{}) (*structpb.Struct, error) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	s := &structpb.Struct{}
+	if err = protojson.Unmarshal(encoded, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// fromStruct decodes the google.protobuf.Struct of a request into the arguments of its method
+func fromStruct(s *structpb.Struct, args interface{}) error {
+	encoded, err := protojson.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, args)
+}
@@ -0,0 +1,109 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// serviceDesc describes the Plugin service of plugin.proto, which is served by a database.Database
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*database.Database)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod("Connect", func() proto.Message { return &structpb.Struct{} }, func(db database.Database, req proto.Message) error {
+			var args ConnectArgs
+			if err := fromStruct(req.(*structpb.Struct), &args); err != nil {
+				return err
+			}
+			return db.Connect(args.ConnectionString, args.Database)
+		}),
+		unaryMethod("Insert", func() proto.Message { return &structpb.Struct{} }, func(db database.Database, req proto.Message) error {
+			var args InsertArgs
+			if err := fromStruct(req.(*structpb.Struct), &args); err != nil {
+				return err
+			}
+			return db.Insert(args.Data, args.Route)
+		}),
+		unaryMethod("Ping", func() proto.Message { return &emptypb.Empty{} }, func(database.Database, proto.Message) error {
+			return nil
+		}),
+		unaryMethod("Close", func() proto.Message { return &emptypb.Empty{} }, func(db database.Database, _ proto.Message) error {
+			return db.Close()
+		}),
+	},
+	Metadata: "plugin.proto",
+}
+
+// unaryMethod returns the description of a method that decodes its request, calls the database
+// and answers with google.protobuf.Empty or the error of the database
+func unaryMethod(name string, newRequest func() proto.Message, call func(db database.Database, req proto.Message) error) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newRequest()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if err := call(srv.(database.Database), req); err != nil {
+				return nil, status.Error(codes.Unknown, err.Error())
+			}
+			return &emptypb.Empty{}, nil
+		},
+	}
+}
+
+// stopOnDisconnect stops the server once the connection of konsume is closed
+type stopOnDisconnect struct {
+	stop func()
+}
+
+func (h *stopOnDisconnect) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+func (h *stopOnDisconnect) HandleRPC(context.Context, stats.RPCStats) {}
+func (h *stopOnDisconnect) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *stopOnDisconnect) HandleConn(_ context.Context, s stats.ConnStats) {
+	if _, ok := s.(*stats.ConnEnd); ok {
+		go h.stop()
+	}
+}
+
+// Serve runs a database written in Go as an external plugin, it listens on a unix socket, writes the handshake
+// to stdout and serves the plugin protocol until konsume closes the connection
+func Serve(db database.Database) error {
+	dir, err := os.MkdirTemp("", "konsume-plugin")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	disconnect := &stopOnDisconnect{}
+	grpcServer := grpc.NewServer(grpc.StatsHandler(disconnect))
+	disconnect.stop = grpcServer.Stop
+	grpcServer.RegisterService(&serviceDesc, db)
+
+	fmt.Printf("%s|%d|unix|%s\n", handshakePrefix, ProtocolVersion, socket)
+	return grpcServer.Serve(listener)
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+// unavailableCheckInterval is the interval of the checks of a database that can not be reached
+const unavailableCheckInterval = time.Second
+
+// Pinger is the interface that databases implement to check that the database can be reached. It is not part of
+// Database, like the other optional interfaces, so that Go plugins built against Database keep loading; all built-in
+// drivers implement it and databases that do not are not monitored
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// monitor tracks whether a database can be reached by pinging it
+type monitor struct {
+	name     string
+	pinger   Pinger
+	interval time.Duration
+	timeout  time.Duration
+
+	mu        sync.Mutex
+	err       error
+	available chan struct{}
+	check     chan struct{}
+	done      chan struct{}
+}
+
+var (
+	monitorsMu sync.Mutex
+	monitors   = make(map[string]*monitor)
+)
+
+// Monitor starts checking the database of the configuration at its health check interval, if the database
+// implements Pinger. While a database can not be reached it is checked every second, writes wait for it
+// with WaitAvailable and the connection pool of its driver reconnects once it is reachable again
+func Monitor(cfg config.DatabaseConfig, db Database) {
+	pinger, ok := db.(Pinger)
+	if !ok {
+		return
+	}
+	m := &monitor{
+		name:      cfg.Name,
+		pinger:    pinger,
+		interval:  cfg.HealthCheckInterval,
+		timeout:   cfg.ReconnectTimeout,
+		available: make(chan struct{}),
+		check:     make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	close(m.available)
+	if m.interval <= 0 {
+		m.interval = common.DefaultDatabaseHealthCheckInterval
+	}
+	if m.timeout <= 0 {
+		m.timeout = common.DefaultDatabaseReconnectTimeout
+	}
+
+	monitorsMu.Lock()
+	if previous, ok := monitors[cfg.Name]; ok {
+		close(previous.done)
+	}
+	monitors[cfg.Name] = m
+	monitorsMu.Unlock()
+
+	go m.run()
+}
+
+// StopMonitoring stops checking the databases
+func StopMonitoring() {
+	monitorsMu.Lock()
+	defer monitorsMu.Unlock()
+	for name, m := range monitors {
+		close(m.done)
+		delete(monitors, name)
+	}
+}
+
+// Health returns the error of the last check of each monitored database, which is nil for the databases that can be reached
+func Health() map[string]error {
+	monitorsMu.Lock()
+	defer monitorsMu.Unlock()
+	health := make(map[string]error, len(monitors))
+	for name, m := range monitors {
+		m.mu.Lock()
+		health[name] = m.err
+		m.mu.Unlock()
+	}
+	return health
+}
+
+// WaitAvailable waits until the database can be reached again if its last check failed, and returns the error
+// of the check if it can not be reached within the reconnect timeout. Databases that are not monitored are available
+func WaitAvailable(name string) error {
+	m := monitorFor(name)
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	available := m.available
+	m.mu.Unlock()
+
+	timer := time.NewTimer(m.timeout)
+	defer timer.Stop()
+	select {
+	case <-available:
+		return nil
+	case <-m.done:
+		return nil
+	case <-timer.C:
+		m.mu.Lock()
+		err := m.err
+		m.mu.Unlock()
+		if err == nil {
+			// the database became available just as the timeout elapsed
+			return nil
+		}
+		return fmt.Errorf("database %s is not available: %w", name, err)
+	}
+}
+
+// ReportFailure checks the database right away after a write failed, so that the following writes wait
+// for the database if it can not be reached instead of failing as well
+func ReportFailure(name string) {
+	m := monitorFor(name)
+	if m == nil {
+		return
+	}
+	select {
+	case m.check <- struct{}{}:
+	default:
+	}
+}
+
+// ConfigurePool applies the connection pool configuration to a SQL database, fields that are not set keep
+// the settings of the driver
+func ConfigurePool(db *sql.DB, cfg *config.PoolConfig) {
+	if cfg == nil {
+		return
+	}
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// WriteContext returns the context of a write, which is cancelled after the statement timeout of the pool configuration
+func WriteContext(cfg *config.PoolConfig) (context.Context, context.CancelFunc) {
+	if cfg == nil || cfg.StatementTimeout == 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), cfg.StatementTimeout)
+}
+
+func monitorFor(name string) *monitor {
+	monitorsMu.Lock()
+	defer monitorsMu.Unlock()
+	return monitors[name]
+}
+
+// run checks the database until the monitor is stopped
+func (m *monitor) run() {
+	wait := m.interval
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-m.done:
+			timer.Stop()
+			return
+		case <-m.check:
+		case <-timer.C:
+		}
+		timer.Stop()
+
+		if m.ping() {
+			wait = m.interval
+		} else {
+			wait = unavailableCheckInterval
+		}
+	}
+}
+
+// ping checks the database and records whether it can be reached
+func (m *monitor) ping() bool {
+	timeout := m.interval
+	if timeout > m.timeout {
+		timeout = m.timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := m.pinger.Ping(ctx)
+	cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wasAvailable := m.err == nil
+	m.err = err
+	switch {
+	case err != nil && wasAvailable:
+		slog.Error("Database is not available", "database", m.name, "error", err)
+		m.available = make(chan struct{})
+	case err == nil && !wasAvailable:
+		slog.Info("Database is available again", "database", m.name)
+		close(m.available)
+	}
+	return err == nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+type pingDatabase struct {
+	testDatabase
+
+	mu  sync.Mutex
+	err error
+}
+
+func (d *pingDatabase) Ping(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.err
+}
+
+func (d *pingDatabase) setErr(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+}
+
+func TestMonitor_WaitAvailable(t *testing.T) {
+	defer StopMonitoring()
+	db := &pingDatabase{}
+	Monitor(config.DatabaseConfig{Name: "monitored", HealthCheckInterval: time.Hour, ReconnectTimeout: 5 * time.Second}, db)
+
+	if err := WaitAvailable("monitored"); err != nil {
+		t.Fatalf("WaitAvailable() error = %v", err)
+	}
+
+	db.setErr(errors.New("connection refused"))
+	ReportFailure("monitored")
+	deadline := time.Now().Add(time.Second)
+	for Health()["monitored"] == nil && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if Health()["monitored"] == nil {
+		t.Fatal("expected the failed check to be reported by Health()")
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		db.setErr(nil)
+	}()
+	start := time.Now()
+	if err := WaitAvailable("monitored"); err != nil {
+		t.Fatalf("WaitAvailable() error = %v", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("expected WaitAvailable() to wait until the database is available again")
+	}
+	if err := Health()["monitored"]; err != nil {
+		t.Errorf("expected the database to be healthy, got %v", err)
+	}
+}
+
+func TestMonitor_WaitAvailableTimeout(t *testing.T) {
+	defer StopMonitoring()
+	db := &pingDatabase{err: errors.New("connection refused")}
+	Monitor(config.DatabaseConfig{Name: "unavailable", HealthCheckInterval: time.Hour, ReconnectTimeout: 200 * time.Millisecond}, db)
+
+	ReportFailure("unavailable")
+	time.Sleep(50 * time.Millisecond)
+	if err := WaitAvailable("unavailable"); err == nil {
+		t.Error("expected an error when the database does not become available within the reconnect timeout")
+	}
+}
+
+func TestWaitAvailable_NotMonitored(t *testing.T) {
+	Monitor(config.DatabaseConfig{Name: "no-ping"}, &testDatabase{})
+	if err := WaitAvailable("no-ping"); err != nil {
+		t.Errorf("expected databases without Ping to be available, got %v", err)
+	}
+	if _, ok := Health()["no-ping"]; ok {
+		t.Error("expected databases without Ping not to be monitored")
+	}
+}
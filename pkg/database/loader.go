@@ -10,7 +10,8 @@ import (
 	"github.com/bugrakocabay/konsume/pkg/common"
 )
 
-// LoadDatabasePlugin loads the database plugin based on the database type
+// LoadDatabasePlugin loads the database driver from the Go plugin of the database type,
+// which is only used by New for the drivers that are excluded from the build
 func LoadDatabasePlugin(dbType string) (Database, error) {
 	pluginPath := getPluginPath(dbType)
 	if pluginPath == "" {
@@ -28,6 +29,16 @@ func getPluginPath(dbType string) string {
 		pluginFile = "postgres"
 	case common.DatabaseTypeMongoDB:
 		pluginFile = "mongodb"
+	case common.DatabaseTypeMySQL:
+		pluginFile = "mysql"
+	case common.DatabaseTypeSQLite:
+		pluginFile = "sqlite"
+	case common.DatabaseTypeClickHouse:
+		pluginFile = "clickhouse"
+	case common.DatabaseTypeElasticsearch:
+		pluginFile = "elasticsearch"
+	case common.DatabaseTypeRedis:
+		pluginFile = "redis"
 	default:
 		return ""
 	}
@@ -61,7 +72,7 @@ func loadPlugin(path string) (Database, error) {
 	}
 	dbPlugin, ok := symbol.(Database)
 	if !ok {
-		return nil, err
+		return nil, fmt.Errorf("plugin %s does not implement the database interface", path)
 	}
 	return dbPlugin, nil
 }
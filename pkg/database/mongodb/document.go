@@ -0,0 +1,105 @@
+package mongodb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// idField is the field of the ID of a MongoDB document
+const idField = "_id"
+
+// fields returns the fields of the document of a message by their dot separated paths. Mapping keys can be paths
+// into the message such as user.name and mapped fields can be paths in the document such as profile.name.
+// The unmapped keys of the message are kept as they are unless the route has a strict mapping
+func fields(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig, parseTimestamps bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	if !dbRouteConfig.StrictMapping {
+		for key, value := range data {
+			if _, mapped := dbRouteConfig.Mapping[key]; !mapped {
+				result[key] = convert(value, parseTimestamps)
+			}
+		}
+	}
+	for key, field := range dbRouteConfig.Mapping {
+		if value, ok := util.LookupPath(data, key); ok {
+			result[field] = convert(value, parseTimestamps)
+		}
+	}
+	if dbRouteConfig.IDField != "" {
+		if id, ok := util.LookupPath(data, dbRouteConfig.IDField); ok {
+			result[idField] = convert(id, parseTimestamps)
+		}
+	}
+	return result
+}
+
+// document builds the nested document of the fields, a field such as profile.name is stored in the profile object
+func document(fields map[string]interface{}) (bson.M, error) {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	// parents are sorted before their children, so that a conflict is found when the child is added
+	sort.Strings(paths)
+
+	doc := bson.M{}
+	for _, path := range paths {
+		parts := strings.Split(path, ".")
+		parent := doc
+		for i, part := range parts[:len(parts)-1] {
+			switch child := parent[part].(type) {
+			case nil:
+				nested := bson.M{}
+				parent[part] = nested
+				parent = nested
+			case bson.M:
+				parent = child
+			case map[string]interface{}:
+				// an object of the message that is kept as it is gets the mapped fields added to a copy of it
+				nested := make(bson.M, len(child)+1)
+				for key, value := range child {
+					nested[key] = value
+				}
+				parent[part] = nested
+				parent = nested
+			default:
+				return nil, fmt.Errorf("field %s conflicts with field %s", path, strings.Join(parts[:i+1], "."))
+			}
+		}
+		parent[parts[len(parts)-1]] = fields[path]
+	}
+	return doc, nil
+}
+
+// convert converts the string values in RFC 3339 format of a value into dates if parseTimestamps is enabled
+func convert(value interface{}, parseTimestamps bool) interface{} {
+	if !parseTimestamps {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = convert(item, parseTimestamps)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = convert(item, parseTimestamps)
+		}
+		return converted
+	}
+	return value
+}
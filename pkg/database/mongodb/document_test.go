@@ -0,0 +1,146 @@
+package mongodb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestDocument(t *testing.T) {
+	data := map[string]interface{}{
+		"id":        "u1",
+		"user":      map[string]interface{}{"name": "John", "age": float64(30)},
+		"createdAt": "2024-01-02T15:04:05Z",
+		"extra":     true,
+	}
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		cfg             config.DatabaseRouteConfig
+		parseTimestamps bool
+		expected        bson.M
+	}{
+		{
+			name: "unmapped keys are kept",
+			cfg:  config.DatabaseRouteConfig{Mapping: map[string]string{"id": "userId"}},
+			expected: bson.M{
+				"userId":    "u1",
+				"user":      map[string]interface{}{"name": "John", "age": float64(30)},
+				"createdAt": "2024-01-02T15:04:05Z",
+				"extra":     true,
+			},
+		},
+		{
+			name: "strict nested mapping with id and timestamps",
+			cfg: config.DatabaseRouteConfig{
+				Mapping:       map[string]string{"user.name": "profile.name", "user.age": "profile.age", "createdAt": "created"},
+				IDField:       "id",
+				StrictMapping: true,
+			},
+			parseTimestamps: true,
+			expected: bson.M{
+				"_id":     "u1",
+				"profile": bson.M{"name": "John", "age": float64(30)},
+				"created": created,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := document(fields(data, tc.cfg, tc.parseTimestamps))
+			if err != nil {
+				t.Fatalf("document() error = %v", err)
+			}
+			if !reflect.DeepEqual(doc, tc.expected) {
+				t.Errorf("document() = %v, expected %v", doc, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDocument_Conflict(t *testing.T) {
+	if _, err := document(map[string]interface{}{"profile": "John", "profile.name": "John"}); err == nil {
+		t.Error("expected an error when a field is nested into a field that is not an object")
+	}
+}
+
+func TestWriteModel_Update(t *testing.T) {
+	cfg := config.DatabaseRouteConfig{
+		Mode:          common.DatabaseRouteModeUpdate,
+		Keys:          []string{"id"},
+		IDField:       "id",
+		Mapping:       map[string]string{"id": "userId", "user.name": "profile.name"},
+		StrictMapping: true,
+	}
+	data := map[string]interface{}{"id": "u1", "user": map[string]interface{}{"name": "John"}}
+
+	model, err := writeModel(fields(data, cfg, false), cfg)
+	if err != nil {
+		t.Fatalf("writeModel() error = %v", err)
+	}
+	update, ok := model.(*mongo.UpdateOneModel)
+	if !ok {
+		t.Fatalf("expected an update model, got %T", model)
+	}
+	if !reflect.DeepEqual(update.Filter, bson.M{"userId": "u1"}) {
+		t.Errorf("unexpected filter %v", update.Filter)
+	}
+	if !reflect.DeepEqual(update.Update, bson.M{"$set": bson.M{"profile.name": "John"}}) {
+		t.Errorf("unexpected update %v", update.Update)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	convertErr := errors.New("field profile.name conflicts with field profile")
+	// the second row could not be converted, so the written documents are the rows 0, 2 and 3
+	errs := []error{nil, convertErr, nil, nil}
+	rows := []int{0, 2, 3}
+	duplicate := mongo.WriteError{Index: 1, Code: 11000, Message: "duplicate key"}
+
+	err := batchError(mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: duplicate}}}, rows, errs)
+	var batchErr *database.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected a BatchError, got %v", err)
+	}
+	expected := []error{nil, convertErr, mongo.BulkWriteError{WriteError: duplicate}, nil}
+	if !reflect.DeepEqual(batchErr.Errors, expected) {
+		t.Errorf("unexpected row errors %v", batchErr.Errors)
+	}
+
+	writeConcernErr := mongo.BulkWriteException{WriteConcernError: &mongo.WriteConcernError{Message: "timeout"}}
+	if err = batchError(writeConcernErr, rows, make([]error, 4)); errors.As(err, &batchErr) || err == nil {
+		t.Errorf("expected a write concern error to fail the whole batch, got %v", err)
+	}
+	if err = batchError(nil, rows, make([]error, 4)); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	pool := &config.PoolConfig{StatementTimeout: 2 * time.Second}
+	tests := []struct {
+		name     string
+		plugin   MongoDBPlugin
+		expected time.Duration
+	}{
+		{name: "default", plugin: MongoDBPlugin{cfg: &config.MongoDBConfig{}}, expected: common.DefaultMongoDBTimeout},
+		{name: "pool statement timeout", plugin: MongoDBPlugin{cfg: &config.MongoDBConfig{WriteConcern: "majority"}, pool: pool}, expected: 2 * time.Second},
+		{name: "mongodb timeout", plugin: MongoDBPlugin{cfg: &config.MongoDBConfig{Timeout: time.Second}, pool: pool}, expected: time.Second},
+	}
+
+	for _, tc := range tests {
+		if timeout := tc.plugin.timeout(); timeout != tc.expected {
+			t.Errorf("%s: timeout() = %s, expected %s", tc.name, timeout, tc.expected)
+		}
+	}
+}
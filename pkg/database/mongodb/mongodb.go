@@ -0,0 +1,260 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+type MongoDBPlugin struct {
+	db   *mongo.Database
+	cfg  *config.MongoDBConfig
+	pool *config.PoolConfig
+}
+
+// Configure stores the write concern, timeout, timestamp and connection pool configuration
+// that is used when connecting and writing
+func (m *MongoDBPlugin) Configure(cfg config.DatabaseConfig) error {
+	m.cfg = cfg.MongoDBConfig
+	m.pool = cfg.Pool
+	return nil
+}
+
+// Connect establishes a connection to the MongoDB database
+func (m *MongoDBPlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to MongoDB database")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	clientOptions := options.Client().ApplyURI(connectionString)
+	if wc := m.writeConcern(); wc != nil {
+		clientOptions.SetWriteConcern(wc)
+	}
+	if m.pool != nil {
+		if m.pool.MaxOpenConns > 0 {
+			clientOptions.SetMaxPoolSize(uint64(m.pool.MaxOpenConns))
+		}
+		if m.pool.ConnMaxIdleTime > 0 {
+			clientOptions.SetMaxConnIdleTime(m.pool.ConnMaxIdleTime)
+		}
+	}
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return err
+	}
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		return err
+	}
+	m.db = client.Database(dbName)
+	slog.Info("Connected to the MongoDB database")
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (m *MongoDBPlugin) Ping(ctx context.Context) error {
+	return m.db.Client().Ping(ctx, nil)
+}
+
+// Insert stores data into the MongoDB database using the mode of the route
+func (m *MongoDBPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	documentFields := fields(data, dbRouteConfig, m.parseTimestamps())
+	collection := m.db.Collection(dbRouteConfig.Collection)
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+
+	var err error
+	switch dbRouteConfig.Mode {
+	case "", common.DatabaseRouteModeInsert:
+		var doc bson.M
+		if doc, err = document(documentFields); err == nil {
+			_, err = collection.InsertOne(ctx, doc)
+		}
+	default:
+		var model mongo.WriteModel
+		model, err = writeModel(documentFields, dbRouteConfig)
+		if err == nil {
+			_, err = collection.BulkWrite(ctx, []mongo.WriteModel{model})
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to write data into MongoDB", "error", err, "collection", dbRouteConfig.Collection)
+		return fmt.Errorf("error writing data into MongoDB collection %s: %w", dbRouteConfig.Collection, err)
+	}
+
+	slog.Info("Data written into MongoDB collection successfully", "collection", dbRouteConfig.Collection)
+	return nil
+}
+
+// InsertMany stores the data of multiple messages into the MongoDB database with a single unordered InsertMany,
+// or a single unordered BulkWrite for the upsert, update and delete modes. The documents that could not be
+// written are reported in a database.BatchError, so that only their messages are failed and written again
+func (m *MongoDBPlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	collection := m.db.Collection(dbRouteConfig.Collection)
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout())
+	defer cancel()
+
+	errs := make([]error, len(data))
+	// rows holds the index in data of each document or model that is written
+	var rows []int
+	var err error
+	switch dbRouteConfig.Mode {
+	case "", common.DatabaseRouteModeInsert:
+		var documents []interface{}
+		for i, row := range data {
+			doc, docErr := document(fields(row, dbRouteConfig, m.parseTimestamps()))
+			if docErr != nil {
+				errs[i] = docErr
+				continue
+			}
+			documents = append(documents, doc)
+			rows = append(rows, i)
+		}
+		if len(documents) > 0 {
+			_, err = collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		}
+	default:
+		var models []mongo.WriteModel
+		for i, row := range data {
+			model, modelErr := writeModel(fields(row, dbRouteConfig, m.parseTimestamps()), dbRouteConfig)
+			if modelErr != nil {
+				errs[i] = modelErr
+				continue
+			}
+			models = append(models, model)
+			rows = append(rows, i)
+		}
+		if len(models) > 0 {
+			_, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		}
+	}
+	if err = batchError(err, rows, errs); err != nil {
+		slog.Error("Failed to write batch into MongoDB", "error", err, "collection", dbRouteConfig.Collection)
+		return err
+	}
+	slog.Info("Batch written into MongoDB collection successfully", "collection", dbRouteConfig.Collection, "documents", len(data))
+	return nil
+}
+
+// batchError returns a database.BatchError with the errors of the rows that could not be written, which are the rows
+// that could not be converted into documents and the rows of the write errors of an unordered write.
+// An error that can not be attributed to rows, such as a write concern or network error, fails the whole batch
+func batchError(err error, rows []int, errs []error) error {
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+			return fmt.Errorf("error writing batch into MongoDB: %w", err)
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index < 0 || writeErr.Index >= len(rows) {
+				return fmt.Errorf("error writing batch into MongoDB: %w", err)
+			}
+			errs[rows[writeErr.Index]] = writeErr
+		}
+	}
+	for _, rowErr := range errs {
+		if rowErr != nil {
+			return &database.BatchError{Errors: errs}
+		}
+	}
+	return nil
+}
+
+// writeModel returns the write model of the upsert, update or delete mode, filtering documents by the mapped keys.
+// Updates set the fields by their paths, so that the other fields of nested objects are kept
+func writeModel(documentFields map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) (mongo.WriteModel, error) {
+	filter := bson.M{}
+	for _, key := range dbRouteConfig.Keys {
+		field := dbRouteConfig.Mapping[key]
+		value, ok := documentFields[field]
+		if !ok {
+			return nil, fmt.Errorf("key field %s has no value in the message", field)
+		}
+		filter[field] = value
+	}
+
+	switch dbRouteConfig.Mode {
+	case common.DatabaseRouteModeUpsert:
+		doc, err := document(documentFields)
+		if err != nil {
+			return nil, err
+		}
+		return mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(doc).SetUpsert(true), nil
+	case common.DatabaseRouteModeUpdate:
+		update := bson.M{}
+		for field, value := range documentFields {
+			// the ID of a document can not be changed
+			if _, ok := filter[field]; !ok && field != idField {
+				update[field] = value
+			}
+		}
+		if len(update) == 0 {
+			return nil, fmt.Errorf("update mode requires at least one mapped field that is not a key")
+		}
+		return mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": update}), nil
+	case common.DatabaseRouteModeDelete:
+		return mongo.NewDeleteOneModel().SetFilter(filter), nil
+	default:
+		return nil, fmt.Errorf("unsupported database route mode: %s", dbRouteConfig.Mode)
+	}
+}
+
+// writeConcern returns the configured write concern, or nil to use the write concern of the connection string
+func (m *MongoDBPlugin) writeConcern() *writeconcern.WriteConcern {
+	if m.cfg == nil || (m.cfg.WriteConcern == "" && m.cfg.Journal == nil) {
+		return nil
+	}
+	wc := &writeconcern.WriteConcern{Journal: m.cfg.Journal}
+	if w, err := strconv.Atoi(m.cfg.WriteConcern); err == nil {
+		wc.W = w
+	} else if m.cfg.WriteConcern != "" {
+		wc.W = m.cfg.WriteConcern
+	}
+	return wc
+}
+
+// timeout returns the maximum duration of a write, the timeout of mongodb-config takes precedence over
+// the statement timeout of the pool configuration
+func (m *MongoDBPlugin) timeout() time.Duration {
+	if m.cfg != nil && m.cfg.Timeout > 0 {
+		return m.cfg.Timeout
+	}
+	if m.pool != nil && m.pool.StatementTimeout > 0 {
+		return m.pool.StatementTimeout
+	}
+	return common.DefaultMongoDBTimeout
+}
+
+// parseTimestamps returns whether string values in RFC 3339 format are stored as dates
+func (m *MongoDBPlugin) parseTimestamps() bool {
+	return m.cfg != nil && m.cfg.ParseTimestamps
+}
+
+// Close closes the connection to the MongoDB database
+func (m *MongoDBPlugin) Close() error {
+	slog.Info("Closing connection to MongoDB database")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := m.db.Client().Disconnect(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func init() {
+	database.Register(common.DatabaseTypeMongoDB, func() database.Database {
+		return &MongoDBPlugin{}
+	})
+}
@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/database/statement"
+	"github.com/bugrakocabay/konsume/pkg/requester"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+type MySQLPlugin struct {
+	db   *sql.DB
+	cfg  *config.MySQLConfig
+	pool *config.PoolConfig
+}
+
+// Configure stores the connection pool and TLS configuration that is used when connecting
+func (p *MySQLPlugin) Configure(cfg config.DatabaseConfig) error {
+	p.cfg = cfg.MySQLConfig
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect establishes a connection to the MySQL database
+func (p *MySQLPlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to MySQL database")
+	dsn, err := mysql.ParseDSN(connectionString)
+	if err != nil {
+		return err
+	}
+	if dbName != "" {
+		dsn.DBName = dbName
+	}
+	if p.cfg != nil && p.cfg.TLS != nil {
+		dsn.TLS, err = requester.NewTLSConfig(p.cfg.TLS)
+		if err != nil {
+			return err
+		}
+		if dsn.TLS.ServerName == "" {
+			host, _, err := net.SplitHostPort(dsn.Addr)
+			if err != nil {
+				host = dsn.Addr
+			}
+			dsn.TLS.ServerName = host
+		}
+	}
+
+	connector, err := mysql.NewConnector(dsn)
+	if err != nil {
+		return err
+	}
+	p.db = sql.OpenDB(connector)
+	database.ConfigurePool(p.db, p.pool)
+	if err = p.db.Ping(); err != nil {
+		return err
+	}
+	slog.Info("Connected to the MySQL database")
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (p *MySQLPlugin) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// PrepareRoute validates the raw SQL statement of the route by preparing it
+func (p *MySQLPlugin) PrepareRoute(dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement == "" {
+		return nil
+	}
+	return statement.ValidateTemplate(p.db, statement.MySQL, dbRouteConfig.Statement)
+}
+
+// Insert stores data into the MySQL database using the mode of the route
+func (p *MySQLPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement != "" {
+		return p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	}
+	stmt, err := statement.Build(statement.MySQL, data, dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	if _, err = p.db.ExecContext(ctx, stmt.Query, stmt.Args...); err != nil {
+		return fmt.Errorf("error executing %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote data into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "values", stmt.Args)
+	return nil
+}
+
+// InsertMany stores the data of multiple messages in a single transaction
+func (p *MySQLPlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	if dbRouteConfig.Statement != "" {
+		if err := statement.ExecTemplate(ctx, p.db, statement.MySQL, data, dbRouteConfig.Statement); err != nil {
+			return fmt.Errorf("error executing statement of route %s: %w", dbRouteConfig.Name, err)
+		}
+		slog.Info("Executed statement of route", "route", dbRouteConfig.Name, "messages", len(data))
+		return nil
+	}
+	statements, err := statement.BuildBatch(statement.MySQL, data, dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	if err = statement.ExecInTransaction(ctx, p.db, statements); err != nil {
+		return fmt.Errorf("error executing batch %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote batch into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "rows", len(data))
+	return nil
+}
+
+// Close terminates the database connection
+func (p *MySQLPlugin) Close() error {
+	if p.db != nil {
+		slog.Info("Closing the MySQL database connection")
+		return p.db.Close()
+	}
+	return nil
+}
+
+func init() {
+	database.Register(common.DatabaseTypeMySQL, func() database.Database {
+		return &MySQLPlugin{}
+	})
+}
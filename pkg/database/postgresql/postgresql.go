@@ -0,0 +1,243 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/database/statement"
+
+	_ "github.com/lib/pq"
+)
+
+type PostgresPlugin struct {
+	db   *sql.DB
+	pool *config.PoolConfig
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// Configure stores the connection pool configuration that is used when connecting
+func (p *PostgresPlugin) Configure(cfg config.DatabaseConfig) error {
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect establishes a connection to the PostgreSQL database
+func (p *PostgresPlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to PostgreSQL database")
+	var err error
+	p.db, err = sql.Open("postgres", connectionString)
+	if err != nil {
+		return err
+	}
+	database.ConfigurePool(p.db, p.pool)
+	if err = p.db.Ping(); err != nil {
+		return err
+	}
+	p.tables = make(map[string]*table)
+	slog.Info("Connected to the PostgreSQL database")
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (p *PostgresPlugin) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// PrepareRoute creates or alters the table of the route if it declares a schema to migrate,
+// and fails if the table does not exist or does not have a mapped column. Raw SQL statements are validated by preparing them
+func (p *PostgresPlugin) PrepareRoute(dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement != "" {
+		return statement.ValidateTemplate(p.db, statement.Postgres, dbRouteConfig.Statement)
+	}
+	for _, stmtCfg := range dbRouteConfig.Statements {
+		if err := p.checkTable(statement.Route(dbRouteConfig, *stmtCfg)); err != nil {
+			return err
+		}
+	}
+	if dbRouteConfig.Table == "" {
+		return nil
+	}
+	if dbRouteConfig.Schema != nil && dbRouteConfig.Schema.Migrate {
+		if err := migrate(p.db, dbRouteConfig); err != nil {
+			return err
+		}
+	}
+	return p.checkTable(dbRouteConfig)
+}
+
+// checkTable introspects the table of the route again and fails if it does not have a mapped column
+func (p *PostgresPlugin) checkTable(dbRouteConfig config.DatabaseRouteConfig) error {
+	p.forgetTable(dbRouteConfig.Table)
+	t, err := p.table(dbRouteConfig.Table)
+	if err != nil {
+		return err
+	}
+	if unknown := t.unknownColumns(dbRouteConfig); len(unknown) > 0 {
+		return fmt.Errorf("table %s of route %s does not have the mapped columns %s", t.name, dbRouteConfig.Name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Insert stores data into the PostgreSQL database using the mode of the route
+func (p *PostgresPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement != "" {
+		return p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	}
+	if len(dbRouteConfig.Statements) > 0 {
+		return p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	}
+	dialect, resolved, err := p.dialect(dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	stmt, err := statement.Build(dialect, data, resolved)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	prepared, err := p.db.PrepareContext(ctx, stmt.Query)
+	if err != nil {
+		p.forgetTable(dbRouteConfig.Table)
+		return fmt.Errorf("error preparing statement: %w", err)
+	}
+	defer prepared.Close()
+
+	_, err = prepared.ExecContext(ctx, stmt.Args...)
+	if err != nil {
+		return fmt.Errorf("error executing %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote data into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "values", stmt.Args)
+	return nil
+}
+
+// InsertMany stores the data of multiple messages in a single transaction
+func (p *PostgresPlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	if dbRouteConfig.Statement != "" {
+		if err := statement.ExecTemplate(ctx, p.db, statement.Postgres, data, dbRouteConfig.Statement); err != nil {
+			return fmt.Errorf("error executing statement of route %s: %w", dbRouteConfig.Name, err)
+		}
+		slog.Info("Executed statement of route", "route", dbRouteConfig.Name, "messages", len(data))
+		return nil
+	}
+	if len(dbRouteConfig.Statements) > 0 {
+		return p.writeStatements(ctx, data, dbRouteConfig)
+	}
+	dialect, resolved, err := p.dialect(dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	statements, err := statement.BuildBatch(dialect, data, resolved)
+	if err != nil {
+		return err
+	}
+	if err = statement.ExecInTransaction(ctx, p.db, statements); err != nil {
+		p.forgetTable(dbRouteConfig.Table)
+		return fmt.Errorf("error executing batch %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote batch into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "rows", len(data))
+	return nil
+}
+
+// writeStatements writes the data of the messages with each of the statements of the route in a single transaction,
+// so that either all rows are written or none of them
+func (p *PostgresPlugin) writeStatements(ctx context.Context, data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	var statements []statement.Statement
+	for _, stmtCfg := range dbRouteConfig.Statements {
+		var rows []map[string]interface{}
+		for _, message := range data {
+			messageRows, err := statement.Rows(message, *stmtCfg)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, messageRows...)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		dialect, resolved, err := p.dialect(statement.Route(dbRouteConfig, *stmtCfg))
+		if err != nil {
+			return err
+		}
+		built, err := statement.BuildBatch(dialect, rows, resolved)
+		if err != nil {
+			return fmt.Errorf("error building statement for table %s: %w", stmtCfg.Table, err)
+		}
+		statements = append(statements, built...)
+	}
+
+	if err := statement.ExecInTransaction(ctx, p.db, statements); err != nil {
+		for _, stmtCfg := range dbRouteConfig.Statements {
+			p.forgetTable(stmtCfg.Table)
+		}
+		return fmt.Errorf("error executing statements of route %s: %w", dbRouteConfig.Name, err)
+	}
+
+	slog.Info("Wrote data into the database", "route", dbRouteConfig.Name, "messages", len(data), "statements", len(statements))
+	return nil
+}
+
+// dialect returns the dialect that converts the values to the column types of the table of the route,
+// and the route with its table and columns resolved to their names in the table
+func (p *PostgresPlugin) dialect(dbRouteConfig config.DatabaseRouteConfig) (*statement.Dialect, config.DatabaseRouteConfig, error) {
+	t, err := p.table(dbRouteConfig.Table)
+	if err != nil {
+		return nil, dbRouteConfig, err
+	}
+	d := *statement.Postgres
+	d.Convert = t.convert
+	return &d, t.route(dbRouteConfig), nil
+}
+
+// table returns the introspected table, which is looked up once and cached until a statement on it fails
+func (p *PostgresPlugin) table(name string) (*table, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t, ok := p.tables[name]; ok {
+		return t, nil
+	}
+	t, err := loadTable(p.db, name)
+	if err != nil {
+		return nil, err
+	}
+	p.tables[name] = t
+	return t, nil
+}
+
+// forgetTable removes the table from the cache, so that changes of the table are picked up by the next statement
+func (p *PostgresPlugin) forgetTable(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tables, name)
+}
+
+// Close terminates the database connection
+func (p *PostgresPlugin) Close() error {
+	if p.db != nil {
+		slog.Info("Closing the PostgreSQL database connection")
+		return p.db.Close()
+	}
+	return nil
+}
+
+func init() {
+	database.Register(common.DatabaseTypePostgresql, func() database.Database {
+		return &PostgresPlugin{}
+	})
+}
@@ -0,0 +1,216 @@
+package postgresql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database/statement"
+
+	"github.com/lib/pq"
+)
+
+var uuidRegex = regexp.MustCompile(`^(?i)\{?[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}}?$`)
+
+// table is the introspected schema of a table
+type table struct {
+	// name is the schema qualified name of the table
+	name string
+
+	// columns are the type names of the columns by column name, arrays are prefixed with an underscore such as _int4
+	columns map[string]string
+}
+
+// loadTable introspects the columns of the table and fails if it does not exist
+func loadTable(db *sql.DB, name string) (*table, error) {
+	t, err := findTable(db, name)
+	if err == nil && t == nil {
+		return nil, fmt.Errorf("table %s does not exist", name)
+	}
+	return t, err
+}
+
+// findTable introspects the columns of the table, or returns nil if it does not exist. A name that is not found
+// as it is written is looked up in lower case, the same as PostgreSQL folds unquoted names
+func findTable(db *sql.DB, name string) (*table, error) {
+	schema, tableName, qualified := strings.Cut(name, ".")
+	if !qualified {
+		schema, tableName = "", name
+	}
+	t, err := queryTable(db, schema, tableName)
+	if err != nil || t != nil {
+		return t, err
+	}
+	return queryTable(db, strings.ToLower(schema), strings.ToLower(tableName))
+}
+
+// queryTable returns the columns of the table, or nil if it does not exist, the current schema is used if schema is empty
+func queryTable(db *sql.DB, schema, name string) (*table, error) {
+	rows, err := db.Query(`SELECT table_schema, column_name, udt_name FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2`, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up columns of table %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	t := &table{columns: make(map[string]string)}
+	for rows.Next() {
+		var columnName, udtName string
+		if err = rows.Scan(&schema, &columnName, &udtName); err != nil {
+			return nil, err
+		}
+		t.columns[columnName] = udtName
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.columns) == 0 {
+		return nil, nil
+	}
+	t.name = schema + "." + name
+	return t, nil
+}
+
+// resolve returns the name of the column a route refers to, which is the name as it is written
+// or in lower case, and whether the table has the column
+func (t *table) resolve(column string) (string, bool) {
+	if _, ok := t.columns[column]; ok {
+		return column, true
+	}
+	if lower := strings.ToLower(column); t.columns[lower] != "" {
+		return lower, true
+	}
+	return column, false
+}
+
+// route returns the route with the table and the mapped columns resolved to their names in the table
+func (t *table) route(dbRouteConfig config.DatabaseRouteConfig) config.DatabaseRouteConfig {
+	resolved := dbRouteConfig
+	resolved.Table = t.name
+	resolved.Mapping = make(map[string]string, len(dbRouteConfig.Mapping))
+	for key, column := range dbRouteConfig.Mapping {
+		resolved.Mapping[key], _ = t.resolve(column)
+	}
+	return resolved
+}
+
+// unknownColumns returns the sorted mapped columns of the route that the table does not have
+func (t *table) unknownColumns(dbRouteConfig config.DatabaseRouteConfig) []string {
+	var unknown []string
+	for _, column := range dbRouteConfig.Mapping {
+		if _, ok := t.resolve(column); !ok {
+			unknown = append(unknown, column)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// convert converts a value decoded from a JSON message into a value of the type of the column
+func (t *table) convert(column string, value interface{}) (interface{}, error) {
+	return convertValue(t.columns[column], value)
+}
+
+// convertValue converts a value into a value of the type, objects and arrays are encoded as JSON unless the type is an array
+func convertValue(udtName string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if elementType, ok := strings.CutPrefix(udtName, "_"); ok {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an array for a %s[] column, got %T", elementType, value)
+		}
+		converted := make([]interface{}, len(items))
+		for i, item := range items {
+			if _, nested := item.([]interface{}); nested && elementType != "json" && elementType != "jsonb" {
+				return nil, fmt.Errorf("multidimensional arrays are not supported")
+			}
+			var err error
+			if converted[i], err = convertValue(elementType, item); err != nil {
+				return nil, err
+			}
+		}
+		return pq.GenericArray{A: converted}, nil
+	}
+
+	switch udtName {
+	case "json", "jsonb":
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	case "timestamp", "timestamptz", "date":
+		// strings are passed as they are, PostgreSQL parses ISO 8601 and its other input formats
+		if seconds, ok := value.(float64); ok {
+			whole, fraction := math.Modf(seconds)
+			return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+		}
+	case "uuid":
+		s, ok := value.(string)
+		if !ok || !uuidRegex.MatchString(s) {
+			return nil, fmt.Errorf("invalid uuid %v", value)
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	}
+	return value, nil
+}
+
+// migrate creates the table of the route from its declared schema if it does not exist, or adds the declared
+// columns the table does not have. Tables of routes with keys get a unique constraint on the key columns,
+// which the upsert mode requires
+func migrate(db *sql.DB, dbRouteConfig config.DatabaseRouteConfig) error {
+	quote := statement.Postgres.QuoteIdentifier
+	columns := make([]string, 0, len(dbRouteConfig.Schema.Columns))
+	for column := range dbRouteConfig.Schema.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	existing, err := findTable(db, dbRouteConfig.Table)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		definitions := make([]string, 0, len(columns)+1)
+		for _, column := range columns {
+			definitions = append(definitions, quote(column)+" "+dbRouteConfig.Schema.Columns[column])
+		}
+		if statement.Mode(dbRouteConfig) != common.DatabaseRouteModeInsert && len(dbRouteConfig.Keys) > 0 {
+			keys := make([]string, len(dbRouteConfig.Keys))
+			for i, key := range dbRouteConfig.Keys {
+				keys[i] = quote(dbRouteConfig.Mapping[key])
+			}
+			definitions = append(definitions, fmt.Sprintf("UNIQUE (%s)", strings.Join(keys, ", ")))
+		}
+		query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", quote(dbRouteConfig.Table), strings.Join(definitions, ", "))
+		if _, err = db.Exec(query); err != nil {
+			return fmt.Errorf("error creating table %s: %w", dbRouteConfig.Table, err)
+		}
+		slog.Info("Created table", "table", dbRouteConfig.Table, "columns", columns)
+		return nil
+	}
+
+	for _, column := range columns {
+		if _, ok := existing.resolve(column); ok {
+			continue
+		}
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s",
+			quote(existing.name), quote(column), dbRouteConfig.Schema.Columns[column])
+		if _, err = db.Exec(query); err != nil {
+			return fmt.Errorf("error adding column %s to table %s: %w", column, existing.name, err)
+		}
+		slog.Info("Added column to table", "table", existing.name, "column", column)
+	}
+	return nil
+}
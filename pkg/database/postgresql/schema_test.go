@@ -0,0 +1,135 @@
+package postgresql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"github.com/lib/pq"
+)
+
+func TestConvertValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		udtName  string
+		value    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "jsonb object",
+			udtName:  "jsonb",
+			value:    map[string]interface{}{"a": float64(1)},
+			expected: `{"a":1}`,
+		},
+		{
+			name:     "json string",
+			udtName:  "json",
+			value:    "text",
+			expected: `"text"`,
+		},
+		{
+			name:     "integer array",
+			udtName:  "_int4",
+			value:    []interface{}{float64(1), float64(2)},
+			expected: pq.GenericArray{A: []interface{}{float64(1), float64(2)}},
+		},
+		{
+			name:     "jsonb array",
+			udtName:  "_jsonb",
+			value:    []interface{}{map[string]interface{}{"a": "b"}},
+			expected: pq.GenericArray{A: []interface{}{`{"a":"b"}`}},
+		},
+		{
+			name:     "unix timestamp",
+			udtName:  "timestamptz",
+			value:    float64(1700000000.5),
+			expected: time.Unix(1700000000, 5e8).UTC(),
+		},
+		{
+			name:     "iso timestamp",
+			udtName:  "timestamp",
+			value:    "2024-01-02T03:04:05Z",
+			expected: "2024-01-02T03:04:05Z",
+		},
+		{
+			name:     "uuid",
+			udtName:  "uuid",
+			value:    "123e4567-e89b-12d3-a456-426614174000",
+			expected: "123e4567-e89b-12d3-a456-426614174000",
+		},
+		{
+			name:     "object into text",
+			udtName:  "text",
+			value:    map[string]interface{}{"a": "b"},
+			expected: `{"a":"b"}`,
+		},
+		{
+			name:     "null",
+			udtName:  "jsonb",
+			value:    nil,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := convertValue(tc.udtName, tc.value)
+			if err != nil {
+				t.Fatalf("convertValue() error = %v", err)
+			}
+			if !reflect.DeepEqual(converted, tc.expected) {
+				t.Errorf("convertValue() = %#v, expected %#v", converted, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConvertValue_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		udtName string
+		value   interface{}
+	}{
+		{name: "invalid uuid", udtName: "uuid", value: "not-a-uuid"},
+		{name: "number as uuid", udtName: "uuid", value: float64(1)},
+		{name: "scalar into array", udtName: "_text", value: "a"},
+		{name: "multidimensional array", udtName: "_int4", value: []interface{}{[]interface{}{float64(1)}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := convertValue(tc.udtName, tc.value); err == nil {
+				t.Errorf("convertValue() expected an error for %v", tc.value)
+			}
+		})
+	}
+}
+
+func TestTable_Route(t *testing.T) {
+	tbl := &table{
+		name:    "public.Users",
+		columns: map[string]string{"id": "int4", "userName": "text", "tags": "_text"},
+	}
+	cfg := config.DatabaseRouteConfig{
+		Name:    "users",
+		Table:   "Users",
+		Mapping: map[string]string{"id": "ID", "name": "userName", "tags": "tags", "email": "email"},
+	}
+
+	resolved := tbl.route(cfg)
+	if resolved.Table != "public.Users" {
+		t.Errorf("route() table = %s, expected public.Users", resolved.Table)
+	}
+	expectedMapping := map[string]string{"id": "id", "name": "userName", "tags": "tags", "email": "email"}
+	if !reflect.DeepEqual(resolved.Mapping, expectedMapping) {
+		t.Errorf("route() mapping = %v, expected %v", resolved.Mapping, expectedMapping)
+	}
+	if cfg.Mapping["id"] != "ID" {
+		t.Error("route() should not modify the mapping of the route")
+	}
+	if unknown := tbl.unknownColumns(cfg); !reflect.DeepEqual(unknown, []string{"email"}) {
+		t.Errorf("unknownColumns() = %v, expected [email]", unknown)
+	}
+}
@@ -0,0 +1,256 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/util"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const commandTimeout = 5 * time.Second
+
+type RedisPlugin struct {
+	client *redis.Client
+	pool   *config.PoolConfig
+}
+
+// Configure stores the connection pool configuration that is used when connecting
+func (p *RedisPlugin) Configure(cfg config.DatabaseConfig) error {
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect establishes a connection to Redis, the connection string is a redis:// or rediss:// URL
+// and the database, if defined, overrides the database number of the URL
+func (p *RedisPlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Connecting to Redis")
+	options, err := redis.ParseURL(connectionString)
+	if err != nil {
+		return err
+	}
+	if dbName != "" {
+		if options.DB, err = strconv.Atoi(dbName); err != nil {
+			return fmt.Errorf("invalid redis database number %s: %w", dbName, err)
+		}
+	}
+	if p.pool != nil {
+		if p.pool.MaxOpenConns > 0 {
+			options.PoolSize = p.pool.MaxOpenConns
+		}
+		if p.pool.MaxIdleConns > 0 {
+			options.MaxIdleConns = p.pool.MaxIdleConns
+		}
+		if p.pool.ConnMaxLifetime > 0 {
+			options.ConnMaxLifetime = p.pool.ConnMaxLifetime
+		}
+		if p.pool.ConnMaxIdleTime > 0 {
+			options.ConnMaxIdleTime = p.pool.ConnMaxIdleTime
+		}
+	}
+	p.client = redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	if err = p.client.Ping(ctx).Err(); err != nil {
+		return err
+	}
+	slog.Info("Connected to Redis")
+	return nil
+}
+
+// Ping checks that Redis can be reached
+func (p *RedisPlugin) Ping(ctx context.Context) error {
+	return p.client.Ping(ctx).Err()
+}
+
+// Insert writes the data with the command of the route
+func (p *RedisPlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	err := p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	var batchErr *database.BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr.Errors[0]
+	}
+	return err
+}
+
+// InsertMany writes the data of multiple messages in a single pipeline,
+// the messages whose commands failed are reported in a database.BatchError
+func (p *RedisPlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	timeout := commandTimeout
+	if p.pool != nil && p.pool.StatementTimeout > 0 {
+		timeout = p.pool.StatementTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	errs := make([]error, len(data))
+	cmds := make([][]redis.Cmder, len(data))
+	// the error of Pipelined is the first failed command, which is reported per message below
+	_, _ = p.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, row := range data {
+			cmds[i], errs[i] = queueCommands(ctx, pipe, row, dbRouteConfig)
+		}
+		return nil
+	})
+
+	failed := 0
+	for i := range data {
+		for _, cmd := range cmds[i] {
+			if err := cmd.Err(); err != nil && errs[i] == nil {
+				errs[i] = fmt.Errorf("error executing %s: %w", cmd.Name(), err)
+			}
+		}
+		if errs[i] != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &database.BatchError{Errors: errs}
+	}
+
+	slog.Info("Wrote data into Redis", "route", dbRouteConfig.Name, "command", dbRouteConfig.Redis.Command, "rows", len(data))
+	return nil
+}
+
+// Close terminates the connection
+func (p *RedisPlugin) Close() error {
+	if p.client != nil {
+		slog.Info("Closing the Redis connection")
+		return p.client.Close()
+	}
+	return nil
+}
+
+// queueCommands adds the commands that write the data of a message to the pipeline
+func queueCommands(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	data map[string]interface{},
+	dbRouteConfig config.DatabaseRouteConfig,
+) ([]redis.Cmder, error) {
+	cfg := dbRouteConfig.Redis
+	key, err := util.ProcessStringTemplate(cfg.Key, data)
+	if err != nil {
+		return nil, fmt.Errorf("error building key: %w", err)
+	}
+	document := mappedDocument(data, dbRouteConfig.Mapping)
+
+	var cmds []redis.Cmder
+	switch cfg.Command {
+	case common.RedisCommandSet:
+		value, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		return []redis.Cmder{pipe.Set(ctx, key, value, cfg.TTL)}, nil
+	case common.RedisCommandHSet:
+		fields, err := fieldValues(document)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, pipe.HSet(ctx, key, fields))
+	case common.RedisCommandLPush:
+		value, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, pipe.LPush(ctx, key, value))
+		if cfg.MaxLength > 0 {
+			cmds = append(cmds, pipe.LTrim(ctx, key, 0, cfg.MaxLength-1))
+		}
+	case common.RedisCommandXAdd:
+		fields, err := fieldValues(document)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, pipe.XAdd(ctx, &redis.XAddArgs{Stream: key, MaxLen: cfg.MaxLength, Approx: cfg.MaxLength > 0, Values: fields}))
+	case common.RedisCommandIncrBy:
+		increment, err := incrementValue(data, cfg.IncrementField)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, pipe.IncrBy(ctx, key, increment))
+	default:
+		return nil, fmt.Errorf("unsupported redis command %s", cfg.Command)
+	}
+	if cfg.TTL > 0 {
+		cmds = append(cmds, pipe.Expire(ctx, key, cfg.TTL))
+	}
+	return cmds, nil
+}
+
+// mappedDocument returns the values of the mapped message keys under their mapped field names
+func mappedDocument(data map[string]interface{}, mapping map[string]string) map[string]interface{} {
+	document := make(map[string]interface{}, len(mapping))
+	for key, field := range mapping {
+		value, ok := data[key]
+		if !ok {
+			slog.Warn("No value found for", "key", key)
+			continue
+		}
+		document[field] = value
+	}
+	return document
+}
+
+// fieldValues returns the fields of a hash or stream entry, objects and arrays are encoded as JSON
+func fieldValues(document map[string]interface{}) (map[string]interface{}, error) {
+	if len(document) == 0 {
+		return nil, errors.New("no mapped fields found in message")
+	}
+	fields := make(map[string]interface{}, len(document))
+	for field, value := range document {
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding %s as JSON: %w", field, err)
+			}
+			fields[field] = string(encoded)
+		case nil:
+			fields[field] = ""
+		default:
+			fields[field] = v
+		}
+	}
+	return fields, nil
+}
+
+// incrementValue returns the integer value of the increment field, or 1 if no field is configured
+func incrementValue(data map[string]interface{}, field string) (int64, error) {
+	if field == "" {
+		return 1, nil
+	}
+	value, ok := util.LookupPath(data, field)
+	if !ok {
+		return 0, fmt.Errorf("increment field %s not found in message", field)
+	}
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("increment field %s must be an integer", field)
+		}
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	default:
+		return 0, fmt.Errorf("increment field %s must be an integer", field)
+	}
+}
+
+func init() {
+	database.Register(common.DatabaseTypeRedis, func() database.Database {
+		return &RedisPlugin{}
+	})
+}
@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func connectTestRedis(t *testing.T) (*RedisPlugin, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	p := &RedisPlugin{}
+	if err := p.Connect("redis://"+server.Addr(), ""); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p, server
+}
+
+func TestRedisPlugin_Set(t *testing.T) {
+	p, server := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Mapping: map[string]string{"id": "id", "name": "user_name"},
+		Redis:   &config.RedisRouteConfig{Command: "set", Key: "user:{{id}}", TTL: time.Hour},
+	}
+
+	if err := p.Insert(map[string]interface{}{"id": float64(7), "name": "John", "unmapped": true}, cfg); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	value, err := server.Get("user:7")
+	if err != nil || value != `{"id":7,"user_name":"John"}` {
+		t.Errorf("unexpected value %s, error = %v", value, err)
+	}
+	if ttl := server.TTL("user:7"); ttl != time.Hour {
+		t.Errorf("expected ttl of 1h, got %s", ttl)
+	}
+}
+
+func TestRedisPlugin_HSet(t *testing.T) {
+	p, server := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Mapping: map[string]string{"name": "name", "tags": "tags"},
+		Redis:   &config.RedisRouteConfig{Command: "hset", Key: "user:{{id}}"},
+	}
+
+	if err := p.Insert(map[string]interface{}{"id": "a", "name": "John", "tags": []interface{}{"x"}}, cfg); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	if name := server.HGet("user:a", "name"); name != "John" {
+		t.Errorf("expected name John, got %s", name)
+	}
+	if tags := server.HGet("user:a", "tags"); tags != `["x"]` {
+		t.Errorf("expected JSON encoded tags, got %s", tags)
+	}
+}
+
+func TestRedisPlugin_LPush(t *testing.T) {
+	p, server := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Mapping: map[string]string{"id": "id"},
+		Redis:   &config.RedisRouteConfig{Command: "lpush", Key: "recent", MaxLength: 2},
+	}
+
+	rows := []map[string]interface{}{{"id": "a"}, {"id": "b"}, {"id": "c"}}
+	if err := p.InsertMany(rows, cfg); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+
+	list, err := server.List("recent")
+	if err != nil || len(list) != 2 || list[0] != `{"id":"c"}` {
+		t.Errorf("expected the 2 newest entries, got %v, error = %v", list, err)
+	}
+}
+
+func TestRedisPlugin_XAdd(t *testing.T) {
+	p, server := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Mapping: map[string]string{"type": "type"},
+		Redis:   &config.RedisRouteConfig{Command: "xadd", Key: "events"},
+	}
+
+	if err := p.Insert(map[string]interface{}{"type": "click"}, cfg); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	stream, err := server.Stream("events")
+	if err != nil || len(stream) != 1 || stream[0].Values[1] != "click" {
+		t.Errorf("unexpected stream %v, error = %v", stream, err)
+	}
+}
+
+func TestRedisPlugin_IncrBy(t *testing.T) {
+	p, server := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Redis: &config.RedisRouteConfig{Command: "incrby", Key: "views:{{page}}", IncrementField: "count"},
+	}
+
+	err := p.InsertMany([]map[string]interface{}{
+		{"page": "home", "count": float64(2)},
+		{"page": "home", "count": float64(3)},
+		{"page": "home", "count": 1.5},
+	}, cfg)
+
+	var batchErr *database.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Errors[0] != nil || batchErr.Errors[1] != nil || batchErr.Errors[2] == nil {
+		t.Fatalf("expected only the fractional increment to fail, got %v", err)
+	}
+	if value, _ := server.Get("views:home"); value != "5" {
+		t.Errorf("expected counter 5, got %s", value)
+	}
+}
+
+func TestRedisPlugin_Insert_MissingKeyField(t *testing.T) {
+	p, _ := connectTestRedis(t)
+	cfg := config.DatabaseRouteConfig{
+		Mapping: map[string]string{"name": "name"},
+		Redis:   &config.RedisRouteConfig{Command: "set", Key: "user:{{id}}"},
+	}
+
+	var batchErr *database.BatchError
+	err := p.Insert(map[string]interface{}{"name": "John"}, cfg)
+	if err == nil || errors.As(err, &batchErr) {
+		t.Errorf("expected the error of the message, got %v", err)
+	}
+}
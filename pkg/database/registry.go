@@ -0,0 +1,62 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// Factory creates a new, unconnected instance of a database driver
+type Factory func() Database
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+// Register makes a database driver available under the database type name, it is called from the init function
+// of the driver packages and panics if the name is registered twice or the factory is nil
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if factory == nil {
+		panic("database: Register factory is nil for " + name)
+	}
+	if _, ok := drivers[name]; ok {
+		panic("database: Register called twice for " + name)
+	}
+	drivers[name] = factory
+}
+
+// Drivers returns the sorted names of the registered database drivers
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates a database of the given type with its registered driver, the driver is loaded
+// from a Go plugin only if it was excluded from the build
+func New(dbType string) (Database, error) {
+	driversMu.RLock()
+	factory, ok := drivers[dbType]
+	driversMu.RUnlock()
+	if ok {
+		return factory(), nil
+	}
+
+	slog.Debug("Database driver is not built in, loading plugin", "type", dbType)
+	db, err := LoadDatabasePlugin(dbType)
+	if err != nil {
+		return nil, fmt.Errorf("database driver %s is not built in and its plugin could not be loaded: %w", dbType, err)
+	}
+	return db, nil
+}
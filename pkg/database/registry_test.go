@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+type testDatabase struct {
+	connected bool
+}
+
+func (d *testDatabase) Connect(connectionString, dbName string) error { return nil }
+
+func (d *testDatabase) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	return nil
+}
+
+func (d *testDatabase) Close() error { return nil }
+
+func TestNew_RegisteredDriver(t *testing.T) {
+	Register("registry-test", func() Database { return &testDatabase{} })
+	defer func() {
+		driversMu.Lock()
+		delete(drivers, "registry-test")
+		driversMu.Unlock()
+	}()
+
+	first, err := New("registry-test")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	second, _ := New("registry-test")
+	if first == second {
+		t.Errorf("expected a new instance for each database")
+	}
+
+	found := false
+	for _, name := range Drivers() {
+		found = found || name == "registry-test"
+	}
+	if !found {
+		t.Errorf("expected registry-test in %v", Drivers())
+	}
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	t.Setenv("KONSUME_PLUGIN_PATH", t.TempDir())
+
+	if _, err := New("registry-unknown"); err == nil {
+		t.Errorf("expected an error for an unknown driver")
+	}
+}
+
+func TestRegister_Twice(t *testing.T) {
+	Register("registry-twice", func() Database { return &testDatabase{} })
+	defer func() {
+		driversMu.Lock()
+		delete(drivers, "registry-twice")
+		driversMu.Unlock()
+		if recover() == nil {
+			t.Errorf("expected Register to panic")
+		}
+	}()
+
+	Register("registry-twice", func() Database { return &testDatabase{} })
+}
@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/database/statement"
+
+	_ "modernc.org/sqlite"
+)
+
+// busyTimeout is the amount of milliseconds SQLite waits for a lock held by another process
+const busyTimeout = 5000
+
+type SQLitePlugin struct {
+	db   *sql.DB
+	pool *config.PoolConfig
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// Configure stores the statement timeout of the pool configuration, the pool itself is limited to a single
+// connection since SQLite allows a single writer
+func (p *SQLitePlugin) Configure(cfg config.DatabaseConfig) error {
+	p.pool = cfg.Pool
+	return nil
+}
+
+// Connect opens the SQLite database file and enables WAL mode
+func (p *SQLitePlugin) Connect(connectionString, dbName string) error {
+	slog.Info("Opening SQLite database", "path", connectionString)
+	var err error
+	p.db, err = sql.Open("sqlite", withBusyTimeout(connectionString))
+	if err != nil {
+		return err
+	}
+	// SQLite allows a single writer, so writes are serialized on one connection instead of failing with SQLITE_BUSY
+	p.db.SetMaxOpenConns(1)
+	if _, err = p.db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		return fmt.Errorf("error enabling WAL mode: %w", err)
+	}
+	p.created = make(map[string]bool)
+	slog.Info("Opened the SQLite database")
+	return nil
+}
+
+// withBusyTimeout adds the busy timeout to the connection string unless it sets one, so that it is applied
+// to every connection the pool opens and not only to the first one
+func withBusyTimeout(connectionString string) string {
+	if strings.Contains(connectionString, "busy_timeout") {
+		return connectionString
+	}
+	separator := "?"
+	if strings.Contains(connectionString, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%s_pragma=busy_timeout(%d)", connectionString, separator, busyTimeout)
+}
+
+// Ping checks that the database file can be read
+func (p *SQLitePlugin) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// PrepareRoute validates the raw SQL statement of the route by compiling it with EXPLAIN, since the driver
+// only prepares statements when they are executed. The tables of the other routes are created when they are first written
+func (p *SQLitePlugin) PrepareRoute(dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement == "" {
+		return nil
+	}
+	stmt, err := statement.BuildTemplate(statement.SQLite, nil, dbRouteConfig.Statement)
+	if err != nil {
+		return err
+	}
+	rows, err := p.db.Query("EXPLAIN "+stmt.Query, stmt.Args...)
+	if err != nil {
+		return fmt.Errorf("invalid statement: %w", err)
+	}
+	return rows.Close()
+}
+
+// Insert stores data into the SQLite database using the mode of the route, creating the table if it does not exist
+func (p *SQLitePlugin) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	if dbRouteConfig.Statement != "" {
+		return p.InsertMany([]map[string]interface{}{data}, dbRouteConfig)
+	}
+	if err := p.ensureTable(dbRouteConfig); err != nil {
+		return err
+	}
+	stmt, err := statement.Build(statement.SQLite, data, dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	if _, err = p.db.ExecContext(ctx, stmt.Query, stmt.Args...); err != nil {
+		return fmt.Errorf("error executing %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote data into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "values", stmt.Args)
+	return nil
+}
+
+// InsertMany stores the data of multiple messages in a single transaction
+func (p *SQLitePlugin) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	ctx, cancel := database.WriteContext(p.pool)
+	defer cancel()
+	if dbRouteConfig.Statement != "" {
+		if err := statement.ExecTemplate(ctx, p.db, statement.SQLite, data, dbRouteConfig.Statement); err != nil {
+			return fmt.Errorf("error executing statement of route %s: %w", dbRouteConfig.Name, err)
+		}
+		slog.Info("Executed statement of route", "route", dbRouteConfig.Name, "messages", len(data))
+		return nil
+	}
+	if err := p.ensureTable(dbRouteConfig); err != nil {
+		return err
+	}
+	statements, err := statement.BuildBatch(statement.SQLite, data, dbRouteConfig)
+	if err != nil {
+		return err
+	}
+	if err = statement.ExecInTransaction(ctx, p.db, statements); err != nil {
+		return fmt.Errorf("error executing batch %s statement: %w", statement.Mode(dbRouteConfig), err)
+	}
+
+	slog.Info("Wrote batch into the database", "table", dbRouteConfig.Table, "mode", statement.Mode(dbRouteConfig), "rows", len(data))
+	return nil
+}
+
+// ensureTable creates the table of the route from the columns of its mapping if it does not exist yet.
+// Routes with keys get a unique constraint on the key columns, which is required by the upsert mode
+func (p *SQLitePlugin) ensureTable(dbRouteConfig config.DatabaseRouteConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.created[dbRouteConfig.Table] {
+		return nil
+	}
+	query := createTableQuery(dbRouteConfig)
+	if _, err := p.db.Exec(query); err != nil {
+		return fmt.Errorf("error creating table %s: %w", dbRouteConfig.Table, err)
+	}
+	p.created[dbRouteConfig.Table] = true
+	return nil
+}
+
+// createTableQuery builds the CREATE TABLE IF NOT EXISTS statement for the mapped columns of the route
+func createTableQuery(dbRouteConfig config.DatabaseRouteConfig) string {
+	seen := make(map[string]bool, len(dbRouteConfig.Mapping))
+	var columns []string
+	for _, column := range dbRouteConfig.Mapping {
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	definitions := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		definitions = append(definitions, statement.SQLite.QuoteIdentifier(column))
+	}
+	if len(dbRouteConfig.Keys) > 0 && dbRouteConfig.Mode != common.DatabaseRouteModeInsert {
+		keys := make([]string, len(dbRouteConfig.Keys))
+		for i, key := range dbRouteConfig.Keys {
+			keys[i] = statement.SQLite.QuoteIdentifier(dbRouteConfig.Mapping[key])
+		}
+		definitions = append(definitions, fmt.Sprintf("UNIQUE (%s)", strings.Join(keys, ", ")))
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)",
+		statement.SQLite.QuoteIdentifier(dbRouteConfig.Table),
+		strings.Join(definitions, ", "),
+	)
+}
+
+// Close closes the SQLite database
+func (p *SQLitePlugin) Close() error {
+	if p.db != nil {
+		slog.Info("Closing the SQLite database")
+		return p.db.Close()
+	}
+	return nil
+}
+
+func init() {
+	database.Register(common.DatabaseTypeSQLite, func() database.Database {
+		return &SQLitePlugin{}
+	})
+}
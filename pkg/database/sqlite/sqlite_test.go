@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func openTestDatabase(t *testing.T) *SQLitePlugin {
+	t.Helper()
+	p := &SQLitePlugin{}
+	if err := p.Connect(filepath.Join(t.TempDir(), "konsume.db"), ""); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+	return p
+}
+
+func countRows(t *testing.T, p *SQLitePlugin, query string, args ...interface{}) int {
+	t.Helper()
+	var count int
+	if err := p.db.QueryRow(query, args...).Scan(&count); err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+	return count
+}
+
+func TestSQLitePlugin_Connect_WAL(t *testing.T) {
+	p := openTestDatabase(t)
+
+	var mode string
+	if err := p.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected journal mode wal, got %s", mode)
+	}
+}
+
+func TestSQLitePlugin_Connect_BusyTimeout(t *testing.T) {
+	dir := t.TempDir()
+	for _, connectionString := range []string{
+		filepath.Join(dir, "plain.db"),
+		"file:" + filepath.Join(dir, "uri.db") + "?_pragma=foreign_keys(1)",
+	} {
+		p := &SQLitePlugin{}
+		if err := p.Connect(connectionString, ""); err != nil {
+			t.Fatalf("Connect() error = %v", err)
+		}
+		var timeout int
+		if err := p.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatalf("QueryRow() error = %v", err)
+		}
+		if timeout != busyTimeout {
+			t.Errorf("expected busy timeout %d for %s, got %d", busyTimeout, connectionString, timeout)
+		}
+		p.Close()
+	}
+
+	if dsn := withBusyTimeout("konsume.db?_pragma=busy_timeout(100)"); dsn != "konsume.db?_pragma=busy_timeout(100)" {
+		t.Errorf("expected the busy timeout of the connection string to be kept, got %s", dsn)
+	}
+}
+
+func TestSQLitePlugin_Insert(t *testing.T) {
+	p := openTestDatabase(t)
+	cfg := config.DatabaseRouteConfig{
+		Table:   "users",
+		Mapping: map[string]string{"id": "user_id", "name": "user_name", "tags": "tags"},
+	}
+
+	err := p.Insert(map[string]interface{}{"id": 1, "name": "John", "tags": []interface{}{"a"}, "unmapped": true}, cfg)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+
+	var name, tags string
+	if err = p.db.QueryRow(`SELECT user_name, tags FROM users WHERE user_id = 1`).Scan(&name, &tags); err != nil {
+		t.Fatalf("QueryRow() error = %v", err)
+	}
+	if name != "John" || tags != `["a"]` {
+		t.Errorf("unexpected row name = %s, tags = %s", name, tags)
+	}
+}
+
+func TestSQLitePlugin_Modes(t *testing.T) {
+	p := openTestDatabase(t)
+	mapping := map[string]string{"id": "id", "name": "name"}
+	upsert := config.DatabaseRouteConfig{Table: "users", Mapping: mapping, Mode: "upsert", Keys: []string{"id"}}
+	update := config.DatabaseRouteConfig{Table: "users", Mapping: mapping, Mode: "update", Keys: []string{"id"}}
+	del := config.DatabaseRouteConfig{Table: "users", Mapping: mapping, Mode: "delete", Keys: []string{"id"}}
+
+	for _, name := range []string{"John", "Jane"} {
+		if err := p.Insert(map[string]interface{}{"id": 1, "name": name}, upsert); err != nil {
+			t.Fatalf("Insert() upsert error = %v", err)
+		}
+	}
+	if count := countRows(t, p, `SELECT COUNT(*) FROM users WHERE name = 'Jane'`); count != 1 {
+		t.Errorf("expected upsert to replace the row, got %d rows", count)
+	}
+
+	if err := p.Insert(map[string]interface{}{"id": 1, "name": "Joe"}, update); err != nil {
+		t.Fatalf("Insert() update error = %v", err)
+	}
+	if count := countRows(t, p, `SELECT COUNT(*) FROM users WHERE name = 'Joe'`); count != 1 {
+		t.Errorf("expected update to change the row, got %d rows", count)
+	}
+
+	if err := p.Insert(map[string]interface{}{"id": 1}, del); err != nil {
+		t.Fatalf("Insert() delete error = %v", err)
+	}
+	if count := countRows(t, p, `SELECT COUNT(*) FROM users`); count != 0 {
+		t.Errorf("expected delete to remove the row, got %d rows", count)
+	}
+}
+
+func TestSQLitePlugin_InsertMany(t *testing.T) {
+	p := openTestDatabase(t)
+	cfg := config.DatabaseRouteConfig{Table: "events", Mapping: map[string]string{"id": "id", "type": "type"}}
+
+	data := []map[string]interface{}{
+		{"id": 1, "type": "created"},
+		{"id": 2},
+		{"id": 3, "type": "deleted"},
+	}
+	if err := p.InsertMany(data, cfg); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	if count := countRows(t, p, `SELECT COUNT(*) FROM events`); count != 3 {
+		t.Errorf("expected 3 rows, got %d", count)
+	}
+}
+
+func TestSQLitePlugin_Statement(t *testing.T) {
+	p := openTestDatabase(t)
+	if _, err := p.db.Exec(`CREATE TABLE counters (name TEXT PRIMARY KEY, total INTEGER)`); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	cfg := config.DatabaseRouteConfig{
+		Name: "counters",
+		Statement: `INSERT INTO counters (name, total) VALUES (:name, :amount)
+			ON CONFLICT (name) DO UPDATE SET total = total + excluded.total -- ':ignored'`,
+	}
+
+	if err := p.PrepareRoute(cfg); err != nil {
+		t.Fatalf("PrepareRoute() error = %v", err)
+	}
+	if err := p.Insert(map[string]interface{}{"name": "a", "amount": 2}, cfg); err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	data := []map[string]interface{}{{"name": "a", "amount": 3}, {"name": "b'; DROP TABLE counters; --", "amount": 1}}
+	if err := p.InsertMany(data, cfg); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	if count := countRows(t, p, "SELECT COUNT(*) FROM counters WHERE name = 'a' AND total = 5"); count != 1 {
+		t.Error("expected the statement to update the existing row")
+	}
+	if count := countRows(t, p, "SELECT COUNT(*) FROM counters"); count != 2 {
+		t.Errorf("expected 2 rows, got %d", count)
+	}
+}
+
+func TestSQLitePlugin_PrepareRoute_InvalidStatement(t *testing.T) {
+	p := openTestDatabase(t)
+	cfg := config.DatabaseRouteConfig{Name: "missing", Statement: "INSERT INTO missing (id) VALUES (:id)"}
+	if err := p.PrepareRoute(cfg); err == nil {
+		t.Error("expected an error for a statement on a table that does not exist")
+	}
+}
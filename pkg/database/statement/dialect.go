@@ -0,0 +1,114 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect describes how statements are written for a SQL database
+type Dialect struct {
+	// Placeholder returns the placeholder of the nth argument, starting from 1
+	Placeholder func(n int) string
+
+	// QuoteIdentifier quotes a possibly qualified table or column name
+	QuoteIdentifier func(identifier string) string
+
+	// UpsertClause returns the clause appended to an insert to set the updates columns of the row
+	// that conflicts on the keys columns, all names are quoted
+	UpsertClause func(updates, keys []string) string
+
+	// MaxParameters is the maximum amount of arguments in a single statement
+	MaxParameters int
+
+	// EncodeJSON encodes objects and arrays as JSON text, for drivers that can not encode them
+	EncodeJSON bool
+
+	// BackslashEscapes is the flag that indicates that a backslash escapes the next character in quoted strings
+	BackslashEscapes bool
+
+	// Convert converts the value of a column before it is bound, for drivers that know the types of the columns.
+	// It replaces EncodeJSON when it is set
+	Convert func(column string, value interface{}) (interface{}, error)
+}
+
+// Postgres is the dialect of PostgreSQL. Identifiers are quoted, so the PostgreSQL driver resolves
+// the names of a route to the names of the table columns, which are lower case unless created quoted
+var Postgres = &Dialect{
+	Placeholder:     func(n int) string { return fmt.Sprintf("$%d", n) },
+	QuoteIdentifier: quoteWith(`"`),
+	UpsertClause:    excludedUpsertClause("EXCLUDED"),
+	MaxParameters:   65535,
+}
+
+// MySQL is the dialect of MySQL and MariaDB. VALUES() is used in the upsert clause instead of row aliases
+// so that the statement also works on MariaDB
+var MySQL = &Dialect{
+	Placeholder:     func(int) string { return "?" },
+	QuoteIdentifier: quoteWith("`"),
+	UpsertClause: func(updates, keys []string) string {
+		assignments := make([]string, 0, len(updates))
+		for _, column := range updates {
+			assignments = append(assignments, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		}
+		if len(assignments) == 0 {
+			// there is nothing to update, so the existing row is kept as it is
+			assignments = append(assignments, fmt.Sprintf("%s = %s", keys[0], keys[0]))
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+	},
+	MaxParameters:    65535,
+	EncodeJSON:       true,
+	BackslashEscapes: true,
+}
+
+// SQLite is the dialect of SQLite
+var SQLite = &Dialect{
+	Placeholder:     func(int) string { return "?" },
+	QuoteIdentifier: quoteWith(`"`),
+	UpsertClause:    excludedUpsertClause("excluded"),
+	MaxParameters:   32766,
+	EncodeJSON:      true,
+}
+
+// excludedUpsertClause returns the ON CONFLICT clause that updates the columns from the excluded row
+func excludedUpsertClause(excluded string) func(updates, keys []string) string {
+	return func(updates, keys []string) string {
+		if len(updates) == 0 {
+			return fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(keys, ", "))
+		}
+		assignments := make([]string, len(updates))
+		for i, column := range updates {
+			assignments[i] = fmt.Sprintf("%s = %s.%s", column, excluded, column)
+		}
+		return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(keys, ", "), strings.Join(assignments, ", "))
+	}
+}
+
+// quoteWith returns a function that quotes each part of a qualified identifier with the quote, doubling embedded quotes
+func quoteWith(quote string) func(identifier string) string {
+	return func(identifier string) string {
+		parts := strings.Split(identifier, ".")
+		for i, part := range parts {
+			parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+		}
+		return strings.Join(parts, ".")
+	}
+}
+
+// quoteAll quotes each of the identifiers
+func (d *Dialect) quoteAll(identifiers []string) []string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = d.QuoteIdentifier(identifier)
+	}
+	return quoted
+}
+
+// assignments builds "column = placeholder" expressions joined by the separator, numbering placeholders from start
+func (d *Dialect) assignments(columns []string, start int, separator string) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("%s = %s", d.QuoteIdentifier(column), d.Placeholder(start+i))
+	}
+	return strings.Join(parts, separator)
+}
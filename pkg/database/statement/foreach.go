@@ -0,0 +1,63 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+// Route returns the route that writes the data of a statement of the route
+func Route(dbRouteConfig config.DatabaseRouteConfig, stmtCfg config.DatabaseStatementConfig) config.DatabaseRouteConfig {
+	return config.DatabaseRouteConfig{
+		Name:     dbRouteConfig.Name,
+		Provider: dbRouteConfig.Provider,
+		Table:    stmtCfg.Table,
+		Mapping:  stmtCfg.Mapping,
+		Mode:     stmtCfg.Mode,
+		Keys:     stmtCfg.Keys,
+		OnError:  dbRouteConfig.OnError,
+	}
+}
+
+// Rows returns the rows a statement writes for the data of a message. Without for-each the message is the only row,
+// otherwise each element of the for-each array is a row that holds the fields of the element, or the element
+// itself under $value if it is not an object, and the mapped fields of the message prefixed with $message.
+func Rows(data map[string]interface{}, stmtCfg config.DatabaseStatementConfig) ([]map[string]interface{}, error) {
+	if stmtCfg.ForEach == "" {
+		return []map[string]interface{}{data}, nil
+	}
+	value, ok := util.LookupPath(data, stmtCfg.ForEach)
+	if !ok || value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("for-each key %s is not an array", stmtCfg.ForEach)
+	}
+
+	rows := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		row := make(map[string]interface{}, len(stmtCfg.Mapping))
+		for key := range stmtCfg.Mapping {
+			path, ok := strings.CutPrefix(key, common.ForEachMessagePrefix)
+			if !ok {
+				continue
+			}
+			if value, ok := util.LookupPath(data, path); ok {
+				row[key] = value
+			}
+		}
+		if fields, ok := item.(map[string]interface{}); ok {
+			for key, value := range fields {
+				row[key] = value
+			}
+		} else {
+			row[common.ForEachValueKey] = item
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
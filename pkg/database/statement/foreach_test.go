@@ -0,0 +1,74 @@
+package statement
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestRows(t *testing.T) {
+	data := map[string]interface{}{
+		"id":    1,
+		"items": []interface{}{map[string]interface{}{"sku": "a", "qty": 2}, map[string]interface{}{"sku": "b"}},
+		"tags":  []interface{}{"x", "y"},
+	}
+
+	tests := []struct {
+		name     string
+		stmtCfg  config.DatabaseStatementConfig
+		expected []map[string]interface{}
+	}{
+		{
+			name:     "message",
+			stmtCfg:  config.DatabaseStatementConfig{Mapping: map[string]string{"id": "id"}},
+			expected: []map[string]interface{}{data},
+		},
+		{
+			name: "for each object",
+			stmtCfg: config.DatabaseStatementConfig{
+				ForEach: "items",
+				Mapping: map[string]string{"$message.id": "order_id", "sku": "sku", "qty": "quantity"},
+			},
+			expected: []map[string]interface{}{
+				{"$message.id": 1, "sku": "a", "qty": 2},
+				{"$message.id": 1, "sku": "b"},
+			},
+		},
+		{
+			name: "for each value",
+			stmtCfg: config.DatabaseStatementConfig{
+				ForEach: "tags",
+				Mapping: map[string]string{"$message.id": "order_id", "$value": "tag"},
+			},
+			expected: []map[string]interface{}{
+				{"$message.id": 1, "$value": "x"},
+				{"$message.id": 1, "$value": "y"},
+			},
+		},
+		{
+			name:     "missing array",
+			stmtCfg:  config.DatabaseStatementConfig{ForEach: "lines", Mapping: map[string]string{"sku": "sku"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := Rows(data, tc.stmtCfg)
+			if err != nil {
+				t.Fatalf("Rows() error = %v", err)
+			}
+			if !reflect.DeepEqual(rows, tc.expected) {
+				t.Errorf("Rows() = %v, expected %v", rows, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRows_NotAnArray(t *testing.T) {
+	stmtCfg := config.DatabaseStatementConfig{ForEach: "id", Mapping: map[string]string{"sku": "sku"}}
+	if _, err := Rows(map[string]interface{}{"id": 1}, stmtCfg); err == nil {
+		t.Error("expected an error when the for-each key is not an array")
+	}
+}
@@ -0,0 +1,300 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+// Statement is a SQL statement with its arguments
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// Mode returns the mode of the route, defaulting to insert
+func Mode(dbRouteConfig config.DatabaseRouteConfig) string {
+	if dbRouteConfig.Mode == "" {
+		return common.DatabaseRouteModeInsert
+	}
+	return dbRouteConfig.Mode
+}
+
+// Build builds the statement that writes the data of a single message with the mode of the route
+func Build(d *Dialect, data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) (Statement, error) {
+	columns, values, err := mappedColumns(d, data, dbRouteConfig)
+	if err != nil {
+		return Statement{}, err
+	}
+	return build(d, dbRouteConfig, columns, [][]interface{}{values})
+}
+
+// BuildBatch builds the statements that write the data of multiple messages with the mode of the route.
+// Inserts and upserts are written with multi-row statements, grouping rows by their mapped columns so that
+// a missing key does not overwrite a column default with NULL. Upserts only write the last row of each key
+// in a group. Updates and deletes get a statement per row
+func BuildBatch(d *Dialect, data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) ([]Statement, error) {
+	groups := make(map[string][][]interface{})
+	groupColumns := make(map[string][]string)
+	var order []string
+	for _, row := range data {
+		columns, values, err := mappedColumns(d, row, dbRouteConfig)
+		if err != nil {
+			return nil, err
+		}
+		signature := strings.Join(columns, ",")
+		if _, ok := groups[signature]; !ok {
+			order = append(order, signature)
+			groupColumns[signature] = columns
+		}
+		groups[signature] = append(groups[signature], values)
+	}
+
+	mode := Mode(dbRouteConfig)
+	multiRow := mode == common.DatabaseRouteModeInsert || mode == common.DatabaseRouteModeUpsert
+
+	var statements []Statement
+	for _, signature := range order {
+		columns := groupColumns[signature]
+		if len(columns) == 0 {
+			continue
+		}
+		rows := groups[signature]
+		if mode == common.DatabaseRouteModeUpsert {
+			rows = lastRowPerKey(columns, rows, keyColumns(dbRouteConfig))
+		}
+		chunkSize := 1
+		if multiRow {
+			chunkSize = d.MaxParameters / len(columns)
+		}
+		for start := 0; start < len(rows); start += chunkSize {
+			end := start + chunkSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			stmt, err := build(d, dbRouteConfig, columns, rows[start:end])
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, stmt)
+		}
+	}
+	return statements, nil
+}
+
+// ExecInTransaction executes the statements in a single transaction, rolling it back if one of them fails
+func ExecInTransaction(ctx context.Context, db *sql.DB, statements []Statement) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt.Query, stmt.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// mappedColumns returns the mapped columns of the data sorted by name and their values
+func mappedColumns(d *Dialect, data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) ([]string, []interface{}, error) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if _, ok := dbRouteConfig.Mapping[key]; !ok {
+			slog.Warn("No mapping found for", "key", key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return dbRouteConfig.Mapping[keys[i]] < dbRouteConfig.Mapping[keys[j]]
+	})
+
+	columns := make([]string, len(keys))
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		columns[i] = dbRouteConfig.Mapping[key]
+		values[i] = data[key]
+		if d.Convert != nil {
+			converted, err := d.Convert(columns[i], values[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("error converting %s: %w", key, err)
+			}
+			values[i] = converted
+			continue
+		}
+		if !d.EncodeJSON {
+			continue
+		}
+		switch value := data[key].(type) {
+		case map[string]interface{}, []interface{}:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error encoding %s as JSON: %w", key, err)
+			}
+			values[i] = string(encoded)
+		}
+	}
+	return columns, values, nil
+}
+
+// keyColumns returns the columns mapped from the keys of the route
+func keyColumns(dbRouteConfig config.DatabaseRouteConfig) []string {
+	columns := make([]string, len(dbRouteConfig.Keys))
+	for i, key := range dbRouteConfig.Keys {
+		columns[i] = dbRouteConfig.Mapping[key]
+	}
+	return columns
+}
+
+// lastRowPerKey keeps the last of the rows with the same key values, since PostgreSQL and SQLite reject a multi-row
+// upsert that updates the same row twice. The kept row takes the place of the first row with its key values
+func lastRowPerKey(columns []string, rows [][]interface{}, keys []string) [][]interface{} {
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		for i, column := range columns {
+			if column == key {
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	if len(indexes) != len(keys) {
+		// a key column is missing, which fails when the statement is built
+		return rows
+	}
+
+	positions := make(map[string]int, len(rows))
+	result := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		values := make([]interface{}, len(indexes))
+		for i, index := range indexes {
+			values[i] = row[index]
+		}
+		key := fmt.Sprintf("%#v", values)
+		if position, ok := positions[key]; ok {
+			result[position] = row
+			continue
+		}
+		positions[key] = len(result)
+		result = append(result, row)
+	}
+	return result
+}
+
+// build builds the statement for the mode of the route. Multiple rows are only supported by the insert and upsert modes
+func build(d *Dialect, dbRouteConfig config.DatabaseRouteConfig, columns []string, rows [][]interface{}) (Statement, error) {
+	mode := Mode(dbRouteConfig)
+	table := d.QuoteIdentifier(dbRouteConfig.Table)
+	keys := keyColumns(dbRouteConfig)
+	if mode != common.DatabaseRouteModeInsert {
+		if err := requireColumns(columns, keys); err != nil {
+			return Statement{}, err
+		}
+	}
+
+	if mode == common.DatabaseRouteModeInsert || mode == common.DatabaseRouteModeUpsert {
+		stmt := buildInsert(d, table, columns, rows)
+		if mode == common.DatabaseRouteModeUpsert {
+			stmt.Query += " " + d.UpsertClause(d.quoteAll(nonKeyColumns(columns, keys)), d.quoteAll(keys))
+		}
+		return stmt, nil
+	}
+
+	if len(rows) != 1 {
+		return Statement{}, fmt.Errorf("%s mode does not support multiple rows in a statement", mode)
+	}
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+
+	var setColumns, conditionColumns []string
+	var setArgs, conditionArgs []interface{}
+	for i, column := range columns {
+		if isKey[column] {
+			conditionColumns = append(conditionColumns, column)
+			conditionArgs = append(conditionArgs, rows[0][i])
+		} else {
+			setColumns = append(setColumns, column)
+			setArgs = append(setArgs, rows[0][i])
+		}
+	}
+
+	switch mode {
+	case common.DatabaseRouteModeUpdate:
+		if len(setColumns) == 0 {
+			return Statement{}, fmt.Errorf("update mode requires at least one mapped column that is not a key")
+		}
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s",
+			table,
+			d.assignments(setColumns, 1, ", "),
+			d.assignments(conditionColumns, len(setColumns)+1, " AND "),
+		)
+		return Statement{Query: query, Args: append(setArgs, conditionArgs...)}, nil
+	case common.DatabaseRouteModeDelete:
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s", table, d.assignments(conditionColumns, 1, " AND "))
+		return Statement{Query: query, Args: conditionArgs}, nil
+	default:
+		return Statement{}, fmt.Errorf("unsupported database route mode: %s", mode)
+	}
+}
+
+// buildInsert builds a multi-row insert statement for the rows
+func buildInsert(d *Dialect, table string, columns []string, rows [][]interface{}) Statement {
+	valueGroups := make([]string, 0, len(rows))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	n := 1
+	for _, row := range rows {
+		placeholders := make([]string, len(row))
+		for j := range row {
+			placeholders[j] = d.Placeholder(n)
+			n++
+		}
+		valueGroups = append(valueGroups, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, row...)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		table,
+		strings.Join(d.quoteAll(columns), ", "),
+		strings.Join(valueGroups, ", "),
+	)
+	return Statement{Query: query, Args: args}
+}
+
+// nonKeyColumns returns the columns that are not keys
+func nonKeyColumns(columns, keys []string) []string {
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+	}
+	var result []string
+	for _, column := range columns {
+		if !isKey[column] {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// requireColumns returns an error if a key column has no value in the data
+func requireColumns(columns, keys []string) error {
+	present := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		present[column] = true
+	}
+	for _, key := range keys {
+		if !present[key] {
+			return fmt.Errorf("key column %s has no value in the message", key)
+		}
+	}
+	return nil
+}
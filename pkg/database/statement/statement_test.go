@@ -0,0 +1,206 @@
+package statement
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestBuild(t *testing.T) {
+	mapping := map[string]string{"id": "user_id", "name": "user_name", "tags": "tags"}
+	data := map[string]interface{}{"id": 1, "name": "John", "tags": []interface{}{"a", "b"}, "unmapped": true}
+
+	tests := []struct {
+		name          string
+		dialect       *Dialect
+		table         string
+		mode          string
+		keys          []string
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "postgres insert",
+			dialect:       Postgres,
+			table:         "users",
+			expectedQuery: `INSERT INTO "users" ("tags", "user_id", "user_name") VALUES ($1, $2, $3)`,
+			expectedArgs:  []interface{}{[]interface{}{"a", "b"}, 1, "John"},
+		},
+		{
+			name:          "postgres upsert",
+			dialect:       Postgres,
+			table:         "users",
+			mode:          "upsert",
+			keys:          []string{"id"},
+			expectedQuery: `INSERT INTO "users" ("tags", "user_id", "user_name") VALUES ($1, $2, $3) ON CONFLICT ("user_id") DO UPDATE SET "tags" = EXCLUDED."tags", "user_name" = EXCLUDED."user_name"`,
+			expectedArgs:  []interface{}{[]interface{}{"a", "b"}, 1, "John"},
+		},
+		{
+			name:          "postgres update",
+			dialect:       Postgres,
+			table:         "users",
+			mode:          "update",
+			keys:          []string{"id"},
+			expectedQuery: `UPDATE "users" SET "tags" = $1, "user_name" = $2 WHERE "user_id" = $3`,
+			expectedArgs:  []interface{}{[]interface{}{"a", "b"}, "John", 1},
+		},
+		{
+			name:          "postgres delete",
+			dialect:       Postgres,
+			table:         "users",
+			mode:          "delete",
+			keys:          []string{"id", "name"},
+			expectedQuery: `DELETE FROM "users" WHERE "user_id" = $1 AND "user_name" = $2`,
+			expectedArgs:  []interface{}{1, "John"},
+		},
+		{
+			name:          "mysql insert",
+			dialect:       MySQL,
+			table:         "app.users",
+			expectedQuery: "INSERT INTO `app`.`users` (`tags`, `user_id`, `user_name`) VALUES (?, ?, ?)",
+			expectedArgs:  []interface{}{`["a","b"]`, 1, "John"},
+		},
+		{
+			name:          "mysql upsert",
+			dialect:       MySQL,
+			table:         "app.users",
+			mode:          "upsert",
+			keys:          []string{"id"},
+			expectedQuery: "INSERT INTO `app`.`users` (`tags`, `user_id`, `user_name`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `tags` = VALUES(`tags`), `user_name` = VALUES(`user_name`)",
+			expectedArgs:  []interface{}{`["a","b"]`, 1, "John"},
+		},
+		{
+			name:          "mysql update",
+			dialect:       MySQL,
+			table:         "users",
+			mode:          "update",
+			keys:          []string{"id"},
+			expectedQuery: "UPDATE `users` SET `tags` = ?, `user_name` = ? WHERE `user_id` = ?",
+			expectedArgs:  []interface{}{`["a","b"]`, "John", 1},
+		},
+		{
+			name:          "sqlite upsert",
+			dialect:       SQLite,
+			table:         "users",
+			mode:          "upsert",
+			keys:          []string{"id"},
+			expectedQuery: `INSERT INTO "users" ("tags", "user_id", "user_name") VALUES (?, ?, ?) ON CONFLICT ("user_id") DO UPDATE SET "tags" = excluded."tags", "user_name" = excluded."user_name"`,
+			expectedArgs:  []interface{}{`["a","b"]`, 1, "John"},
+		},
+		{
+			name:          "sqlite delete",
+			dialect:       SQLite,
+			table:         "users",
+			mode:          "delete",
+			keys:          []string{"id"},
+			expectedQuery: `DELETE FROM "users" WHERE "user_id" = ?`,
+			expectedArgs:  []interface{}{1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.DatabaseRouteConfig{Table: tc.table, Mapping: mapping, Mode: tc.mode, Keys: tc.keys}
+			stmt, err := Build(tc.dialect, data, cfg)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if stmt.Query != tc.expectedQuery {
+				t.Errorf("Build() query = %s, expected %s", stmt.Query, tc.expectedQuery)
+			}
+			if !reflect.DeepEqual(stmt.Args, tc.expectedArgs) {
+				t.Errorf("Build() args = %v, expected %v", stmt.Args, tc.expectedArgs)
+			}
+		})
+	}
+}
+
+func TestBuild_MissingKey(t *testing.T) {
+	cfg := config.DatabaseRouteConfig{
+		Table:   "users",
+		Mapping: map[string]string{"id": "user_id", "name": "user_name"},
+		Mode:    "update",
+		Keys:    []string{"id"},
+	}
+	if _, err := Build(Postgres, map[string]interface{}{"name": "John"}, cfg); err == nil {
+		t.Error("expected an error when the key has no value in the message")
+	}
+}
+
+func TestBuildBatch(t *testing.T) {
+	cfg := config.DatabaseRouteConfig{Table: "users", Mapping: map[string]string{"id": "id", "name": "name"}}
+	data := []map[string]interface{}{
+		{"id": 1, "name": "John"},
+		{"id": 2},
+		{"id": 3, "name": "Jane"},
+	}
+
+	statements, err := BuildBatch(Postgres, data, cfg)
+	if err != nil {
+		t.Fatalf("BuildBatch() error = %v", err)
+	}
+	expected := []Statement{
+		{Query: `INSERT INTO "users" ("id", "name") VALUES ($1, $2), ($3, $4)`, Args: []interface{}{1, "John", 3, "Jane"}},
+		{Query: `INSERT INTO "users" ("id") VALUES ($1)`, Args: []interface{}{2}},
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("BuildBatch() = %v, expected %v", statements, expected)
+	}
+}
+
+func TestBuildBatch_UpsertDuplicateKeys(t *testing.T) {
+	cfg := config.DatabaseRouteConfig{
+		Table:   "users",
+		Mode:    "upsert",
+		Keys:    []string{"id"},
+		Mapping: map[string]string{"id": "id", "name": "name"},
+	}
+	data := []map[string]interface{}{
+		{"id": 1, "name": "John"},
+		{"id": 2, "name": "Jane"},
+		{"id": 1, "name": "Johnny"},
+	}
+
+	statements, err := BuildBatch(SQLite, data, cfg)
+	if err != nil {
+		t.Fatalf("BuildBatch() error = %v", err)
+	}
+	expected := []Statement{
+		{
+			Query: `INSERT INTO "users" ("id", "name") VALUES (?, ?), (?, ?) ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`,
+			Args:  []interface{}{1, "Johnny", 2, "Jane"},
+		},
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("BuildBatch() = %v, expected %v", statements, expected)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	if quoted := MySQL.QuoteIdentifier("we`ird"); quoted != "`we``ird`" {
+		t.Errorf("QuoteIdentifier() = %s, expected backticks to be escaped", quoted)
+	}
+	if quoted := SQLite.QuoteIdentifier(`we"ird`); quoted != `"we""ird"` {
+		t.Errorf("QuoteIdentifier() = %s, expected quotes to be escaped", quoted)
+	}
+}
+
+func TestBuild_Convert(t *testing.T) {
+	d := *SQLite
+	d.Convert = func(column string, value interface{}) (interface{}, error) {
+		if column == "tags" {
+			return "converted", nil
+		}
+		return value, nil
+	}
+	cfg := config.DatabaseRouteConfig{Table: "users", Mapping: map[string]string{"id": "id", "tags": "tags"}}
+
+	stmt, err := Build(&d, map[string]interface{}{"id": 1, "tags": []interface{}{"a"}}, cfg)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !reflect.DeepEqual(stmt.Args, []interface{}{1, "converted"}) {
+		t.Errorf("expected the converted arguments, got %v", stmt.Args)
+	}
+}
@@ -0,0 +1,158 @@
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+// BuildTemplate builds the raw SQL statement of a route for the data of a message. Named placeholders such as :id
+// or :customer.address.city are replaced with the placeholders of the dialect and their values are bound as arguments,
+// so the data of a message is never written into the query itself. Missing fields are bound as NULL
+func BuildTemplate(d *Dialect, data map[string]interface{}, query string) (Statement, error) {
+	var args []interface{}
+	var err error
+	built := scanTemplate(query, d.BackslashEscapes, func(name string) string {
+		value, _ := util.LookupPath(data, name)
+		switch v := value.(type) {
+		case map[string]interface{}, []interface{}:
+			encoded, encodeErr := json.Marshal(v)
+			if encodeErr != nil && err == nil {
+				err = fmt.Errorf("error encoding %s: %w", name, encodeErr)
+			}
+			value = string(encoded)
+		}
+		args = append(args, value)
+		return d.Placeholder(len(args))
+	})
+	if err != nil {
+		return Statement{}, err
+	}
+	return Statement{Query: built, Args: args}, nil
+}
+
+// ExecTemplate executes the raw SQL statement of a route for the data of each message,
+// the statements of multiple messages are executed in a single transaction
+func ExecTemplate(ctx context.Context, db *sql.DB, d *Dialect, data []map[string]interface{}, query string) error {
+	statements := make([]Statement, len(data))
+	for i, row := range data {
+		stmt, err := BuildTemplate(d, row, query)
+		if err != nil {
+			return err
+		}
+		statements[i] = stmt
+	}
+	if len(statements) == 1 {
+		_, err := db.ExecContext(ctx, statements[0].Query, statements[0].Args...)
+		return err
+	}
+	return ExecInTransaction(ctx, db, statements)
+}
+
+// ValidateTemplate prepares the raw SQL statement of a route, so that a statement the database can not parse
+// or that refers to unknown tables or columns fails at startup instead of for each message
+func ValidateTemplate(db *sql.DB, d *Dialect, query string) error {
+	stmt, err := BuildTemplate(d, nil, query)
+	if err != nil {
+		return err
+	}
+	prepared, err := db.Prepare(stmt.Query)
+	if err != nil {
+		return fmt.Errorf("invalid statement: %w", err)
+	}
+	return prepared.Close()
+}
+
+// TemplateNames returns the names of the placeholders of a raw SQL statement in order of appearance. Since the
+// dialect is not known, the names found with and without backslash escapes in strings are returned
+func TemplateNames(query string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, backslashEscapes := range []bool{false, true} {
+		scanTemplate(query, backslashEscapes, func(name string) string {
+			if !backslashEscapes || !seen[name] {
+				names = append(names, name)
+			}
+			seen[name] = true
+			return ""
+		})
+	}
+	return names
+}
+
+// scanTemplate replaces the named placeholders of the query with the result of replace. Quoted strings and
+// identifiers, comments and PostgreSQL casts such as ::int are left as they are. With backslash escapes,
+// a backslash in a quoted string escapes the next character, as in MySQL
+func scanTemplate(query string, backslashEscapes bool, replace func(name string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(query, i, backslashEscapes && c != '`')
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			// doubled quotes escape a quote, which is the same as two adjacent quoted parts
+			b.WriteString(query[i : end+1])
+			i = end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 4
+		case strings.HasPrefix(query[i:], "::"):
+			b.WriteString("::")
+			i += 2
+		case c == ':' && i+1 < len(query) && isNameStart(query[i+1]):
+			end := i + 1
+			for end < len(query) && (isNamePart(query[end]) || query[end] == '.' && end+1 < len(query) && isNamePart(query[end+1])) {
+				end++
+			}
+			b.WriteString(replace(query[i+1 : end]))
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// closingQuote returns the index of the quote that closes the quoted part starting at start, or -1 if it is not closed
+func closingQuote(query string, start int, backslashEscapes bool) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslashEscapes {
+				i++
+			}
+		case query[start]:
+			return i
+		}
+	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
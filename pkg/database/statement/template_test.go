@@ -0,0 +1,94 @@
+package statement
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildTemplate(t *testing.T) {
+	data := map[string]interface{}{
+		"id":       1,
+		"customer": map[string]interface{}{"city": "Berlin"},
+		"tags":     []interface{}{"a"},
+	}
+
+	tests := []struct {
+		name          string
+		dialect       *Dialect
+		query         string
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "postgres",
+			dialect:       Postgres,
+			query:         "UPDATE users SET city = :customer.city, tags = :tags::jsonb WHERE id = :id",
+			expectedQuery: "UPDATE users SET city = $1, tags = $2::jsonb WHERE id = $3",
+			expectedArgs:  []interface{}{"Berlin", `["a"]`, 1},
+		},
+		{
+			name:          "mysql repeated and missing names",
+			dialect:       MySQL,
+			query:         "CALL sync_user(:id, :id, :missing)",
+			expectedQuery: "CALL sync_user(?, ?, ?)",
+			expectedArgs:  []interface{}{1, 1, nil},
+		},
+		{
+			name:          "quotes and comments",
+			dialect:       SQLite,
+			query:         "INSERT INTO \"a:b\" VALUES (':id', :id) /* :id */ -- :id\n",
+			expectedQuery: "INSERT INTO \"a:b\" VALUES (':id', ?) /* :id */ -- :id\n",
+			expectedArgs:  []interface{}{1},
+		},
+		{
+			name:          "escaped quote",
+			dialect:       SQLite,
+			query:         "SELECT 'it''s :id', :id.",
+			expectedQuery: "SELECT 'it''s :id', ?.",
+			expectedArgs:  []interface{}{1},
+		},
+		{
+			name:          "mysql backslash escapes",
+			dialect:       MySQL,
+			query:         `SELECT 'it\'s :id', "a\\", :id, ` + "`a\\`, :id",
+			expectedQuery: `SELECT 'it\'s :id', "a\\", ?, ` + "`a\\`, ?",
+			expectedArgs:  []interface{}{1, 1},
+		},
+		{
+			name:          "backslash without escapes",
+			dialect:       SQLite,
+			query:         `SELECT 'C:\', :id`,
+			expectedQuery: `SELECT 'C:\', ?`,
+			expectedArgs:  []interface{}{1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stmt, err := BuildTemplate(tc.dialect, data, tc.query)
+			if err != nil {
+				t.Fatalf("BuildTemplate() error = %v", err)
+			}
+			if stmt.Query != tc.expectedQuery {
+				t.Errorf("BuildTemplate() query = %s, expected %s", stmt.Query, tc.expectedQuery)
+			}
+			if !reflect.DeepEqual(stmt.Args, tc.expectedArgs) {
+				t.Errorf("BuildTemplate() args = %v, expected %v", stmt.Args, tc.expectedArgs)
+			}
+		})
+	}
+}
+
+func TestTemplateNames(t *testing.T) {
+	names := TemplateNames("SELECT :a, :routes.createUser.body.id, ':b', :a::int")
+	expected := []string{"a", "routes.createUser.body.id", "a"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("TemplateNames() = %v, expected %v", names, expected)
+	}
+
+	names = TemplateNames(`SELECT 'it\'s :a', :b`)
+	expected = []string{"a", "b"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("TemplateNames() = %v, expected the names with and without backslash escapes %v", names, expected)
+	}
+}
@@ -0,0 +1,82 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+// claimTTL is the longest time that a message ID is claimed for while the message is processed,
+// so that the claim of an instance that stops during processing expires and the message can be processed again
+const claimTTL = 5 * time.Minute
+
+// Store is an interface that defines the methods that a store of processed message IDs should implement.
+// Claim atomically reserves an ID that is neither processed nor claimed and reports whether it was reserved,
+// Mark marks a claimed ID as processed and Release gives up the claim of an ID whose processing failed
+type Store interface {
+	Claim(id string) (bool, error)
+	Mark(id string) error
+	Release(id string) error
+	Close() error
+}
+
+// claimDuration returns the duration of a claim, which is at most the ttl of the processed IDs
+func claimDuration(ttl time.Duration) time.Duration {
+	if ttl < claimTTL {
+		return ttl
+	}
+	return claimTTL
+}
+
+// NewStore creates the store defined in the dedup configuration
+func NewStore(cfg *config.DedupConfig, databases []*config.DatabaseConfig) (Store, error) {
+	switch cfg.Store {
+	case common.DedupStoreMemory:
+		return NewMemoryStore(cfg.Size, cfg.TTL), nil
+	case common.DedupStoreRedis:
+		return NewRedisStore(cfg.Redis, cfg.TTL)
+	case common.DedupStoreDatabase:
+		for _, db := range databases {
+			if db.Name == cfg.Database.Provider {
+				return NewSQLStore(db.ConnectionString, cfg.Database.Table, cfg.TTL)
+			}
+		}
+		return nil, fmt.Errorf("database not found for dedup store: %s", cfg.Database.Provider)
+	default:
+		return nil, fmt.Errorf("unsupported dedup store: %s", cfg.Store)
+	}
+}
+
+// MessageID returns the ID of the message, which is the value of the configured key of the delivery metadata
+// when the source is metadata, otherwise the value of the configured field or the SHA-256 of the message
+func MessageID(cfg *config.DedupConfig, messageData map[string]interface{}, msg []byte, metadata map[string]string) (string, error) {
+	if cfg.Source == common.DedupSourceMetadata {
+		id, ok := metadata[cfg.Field]
+		if !ok || id == "" {
+			return "", fmt.Errorf("dedup key %s not found in message metadata", cfg.Field)
+		}
+		return id, nil
+	}
+	if cfg.Field == "" {
+		sum := sha256.Sum256(msg)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	value, ok := util.LookupPath(messageData, cfg.Field)
+	if !ok || value == nil {
+		return "", fmt.Errorf("dedup field %s not found in message", cfg.Field)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
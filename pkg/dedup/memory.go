@@ -0,0 +1,91 @@
+package dedup
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-memory LRU store of processed message IDs with a TTL
+type MemoryStore struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	id      string
+	expires time.Time
+}
+
+// NewMemoryStore creates a MemoryStore that keeps at most size IDs for the given ttl
+func NewMemoryStore(size int, ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Claim reserves the ID for the claim duration unless it is processed or claimed and has not expired
+func (m *MemoryStore) Claim(id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[id]; ok {
+		if time.Now().Before(elem.Value.(*memoryEntry).expires) {
+			m.order.MoveToFront(elem)
+			return false, nil
+		}
+		m.order.Remove(elem)
+		delete(m.entries, id)
+	}
+	m.add(id, time.Now().Add(claimDuration(m.ttl)))
+	return true, nil
+}
+
+// Mark marks the ID as processed, evicting the least recently used ID when the store is full
+func (m *MemoryStore) Mark(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expires := time.Now().Add(m.ttl)
+	if elem, ok := m.entries[id]; ok {
+		elem.Value.(*memoryEntry).expires = expires
+		m.order.MoveToFront(elem)
+		return nil
+	}
+	m.add(id, expires)
+	return nil
+}
+
+// Release removes the claim of the ID
+func (m *MemoryStore) Release(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[id]; ok {
+		m.order.Remove(elem)
+		delete(m.entries, id)
+	}
+	return nil
+}
+
+// add adds the ID to the front of the store, evicting the least recently used IDs when the store is full
+func (m *MemoryStore) add(id string, expires time.Time) {
+	m.entries[id] = m.order.PushFront(&memoryEntry{id: id, expires: expires})
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).id)
+	}
+}
+
+// Close does nothing for the MemoryStore
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package dedup
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestMemoryStore_ClaimAndMark(t *testing.T) {
+	store := NewMemoryStore(10, time.Minute)
+
+	if claimed, _ := store.Claim("a"); !claimed {
+		t.Fatal("expected new ID to be claimed")
+	}
+	if claimed, _ := store.Claim("a"); claimed {
+		t.Fatal("expected claimed ID not to be claimed again")
+	}
+	store.Mark("a")
+	if claimed, _ := store.Claim("a"); claimed {
+		t.Fatal("expected marked ID not to be claimed")
+	}
+}
+
+func TestMemoryStore_Release(t *testing.T) {
+	store := NewMemoryStore(10, time.Minute)
+
+	store.Claim("a")
+	store.Release("a")
+	if claimed, _ := store.Claim("a"); !claimed {
+		t.Fatal("expected released ID to be claimed again")
+	}
+}
+
+func TestMemoryStore_ConcurrentClaim(t *testing.T) {
+	store := NewMemoryStore(10, time.Minute)
+
+	var (
+		wg      sync.WaitGroup
+		claimed atomic.Int32
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ok, _ := store.Claim("a"); ok {
+				claimed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := claimed.Load(); n != 1 {
+		t.Errorf("expected the ID to be claimed once, got %d claims", n)
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	store := NewMemoryStore(2, time.Minute)
+
+	store.Mark("a")
+	store.Mark("b")
+	// touching a makes b the least recently used ID
+	store.Claim("a")
+	store.Mark("c")
+
+	for _, id := range []string{"a", "c"} {
+		if claimed, _ := store.Claim(id); claimed {
+			t.Errorf("expected %s not to be claimed", id)
+		}
+	}
+	if claimed, _ := store.Claim("b"); !claimed {
+		t.Error("expected least recently used ID to be evicted")
+	}
+}
+
+func TestMemoryStore_TTL(t *testing.T) {
+	store := NewMemoryStore(10, 10*time.Millisecond)
+
+	store.Mark("a")
+	time.Sleep(20 * time.Millisecond)
+	if claimed, _ := store.Claim("a"); !claimed {
+		t.Error("expected expired ID to be claimed")
+	}
+}
+
+func TestMessageID(t *testing.T) {
+	msg := []byte(`{"event":{"id":"abc"},"seq":12}`)
+	data := map[string]interface{}{
+		"event": map[string]interface{}{"id": "abc"},
+		"seq":   float64(12),
+	}
+	metadata := map[string]string{
+		common.MetadataMessageID: "orders/0/42",
+		"header.x-request-id":    "req-1",
+	}
+
+	tests := []struct {
+		name     string
+		source   string
+		field    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "nested field", field: "event.id", expected: "abc"},
+		{name: "number field", field: "seq", expected: "12"},
+		{name: "missing field", field: "event.missing", wantErr: true},
+		{name: "message hash", expected: "cce8369b2769d867066b1b20d51dd2693a1d50a46428ff8fa20a4f2c9f88bc36"},
+		{name: "metadata message id", source: common.DedupSourceMetadata, field: common.MetadataMessageID, expected: "orders/0/42"},
+		{name: "metadata header", source: common.DedupSourceMetadata, field: "header.x-request-id", expected: "req-1"},
+		{name: "missing metadata", source: common.DedupSourceMetadata, field: "header.missing", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := MessageID(&config.DedupConfig{Source: tc.source, Field: tc.field}, data, msg, metadata)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("MessageID() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if id != tc.expected {
+				t.Errorf("MessageID() = %s, expected %s", id, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore is a store of processed message IDs in Redis, which can be shared by multiple konsume instances
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore creates a RedisStore and checks the connection
+func NewRedisStore(cfg *config.DedupRedisConfig, ttl time.Duration) (*RedisStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisStore{client: client, prefix: cfg.Prefix, ttl: ttl}, nil
+}
+
+// Claim reserves the ID for the claim duration with SETNX unless it is processed or claimed
+func (r *RedisStore) Claim(id string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.client.SetNX(ctx, r.prefix+id, 0, claimDuration(r.ttl)).Result()
+}
+
+// Mark marks the ID as processed
+func (r *RedisStore) Mark(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.client.Set(ctx, r.prefix+id, 1, r.ttl).Err()
+}
+
+// Release removes the claim of the ID
+func (r *RedisStore) Release(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return r.client.Del(ctx, r.prefix+id).Err()
+}
+
+// Close closes the connection to Redis
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
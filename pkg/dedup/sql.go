@@ -0,0 +1,81 @@
+package dedup
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SQLStore is a store of processed message IDs in a PostgreSQL table
+type SQLStore struct {
+	db    *sql.DB
+	table string
+	ttl   time.Duration
+}
+
+// NewSQLStore connects to the database and creates the table if it does not exist
+func NewSQLStore(connectionString, table string, ttl time.Duration) (*SQLStore, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, expires_at TIMESTAMPTZ NOT NULL)`,
+		quoteIdentifier(table))
+	if _, err = db.Exec(query); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating dedup table: %w", err)
+	}
+	return &SQLStore{db: db, table: quoteIdentifier(table), ttl: ttl}, nil
+}
+
+// Claim reserves the ID for the claim duration unless it is processed or claimed and has not expired,
+// the insert only takes over an existing row once it has expired so that a single caller claims the ID
+func (s *SQLStore) Claim(id string) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %s AS d (id, expires_at) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET expires_at = EXCLUDED.expires_at WHERE d.expires_at <= NOW()`, s.table)
+	result, err := s.db.Exec(query, id, time.Now().Add(claimDuration(s.ttl)))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Mark marks the ID as processed
+func (s *SQLStore) Mark(id string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, expires_at) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET expires_at = EXCLUDED.expires_at`, s.table)
+	_, err := s.db.Exec(query, id, time.Now().Add(s.ttl))
+	return err
+}
+
+// Release removes the claim of the ID
+func (s *SQLStore) Release(id string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, s.table)
+	_, err := s.db.Exec(query, id)
+	return err
+}
+
+// Close closes the connection to the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// quoteIdentifier quotes a table name, keeping a schema qualifier such as "public.table"
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = pq.QuoteIdentifier(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,46 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+)
+
+// readiness is the response of the readiness endpoint
+type readiness struct {
+	Status    string            `json:"status"`
+	Databases map[string]string `json:"databases"`
+}
+
+// InitHealth starts the readiness endpoint, which responds with 503 while one of the databases can not be reached
+func InitHealth(cfg *config.HealthConfig) {
+	mux := http.NewServeMux()
+	mux.HandleFunc(cfg.Path, handleReadiness)
+
+	go func() {
+		log.Fatalln(http.ListenAndServe(fmt.Sprintf(":%d", cfg.Port), mux))
+	}()
+}
+
+// handleReadiness writes the status of each monitored database
+func handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	response := readiness{Status: "ready", Databases: make(map[string]string)}
+	status := http.StatusOK
+	for name, err := range database.Health() {
+		if err != nil {
+			response.Databases[name] = err.Error()
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		response.Databases[name] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,48 @@
+package message
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+// decodeCSV decodes CSV lines with a header line. The rows are stored under $items, and the fields
+// of a message with a single row are also stored at the top level
+func decodeCSV(msg []byte) (map[string]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(msg))
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("no header line")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]interface{}, 0)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+
+	data := make(map[string]interface{}, len(header)+1)
+	if len(rows) == 1 {
+		for key, value := range rows[0].(map[string]interface{}) {
+			data[key] = value
+		}
+	}
+	data[common.MessageItemsKey] = rows
+	return data, nil
+}
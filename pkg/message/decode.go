@@ -0,0 +1,88 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+// decoder decodes a message into the data that is used by templates and mappings
+type decoder func(msg []byte) (map[string]interface{}, error)
+
+var decoders = map[string]decoder{
+	common.MessageFormatJSON: decodeJSON,
+	common.MessageFormatXML:  decodeXML,
+	common.MessageFormatCSV:  decodeCSV,
+	common.MessageFormatForm: decodeForm,
+	common.MessageFormatText: decodeText,
+}
+
+// Decode decodes a message into a map with the format of the queue, messages are decoded as JSON if the queue has no format
+func Decode(qCfg *config.QueueConfig, msg []byte) (map[string]interface{}, error) {
+	format := qCfg.Format
+	if format == "" {
+		format = common.MessageFormatJSON
+	}
+
+	var data map[string]interface{}
+	var err error
+	if decode, ok := decoders[format]; ok {
+		data, err = decode(msg)
+	} else if format == common.MessageFormatAvro || format == common.MessageFormatProtobuf {
+		var d *schemaDecoder
+		if d, err = schemaDecoderFor(qCfg); err == nil {
+			data, err = d.decode(msg)
+		}
+	} else {
+		return nil, fmt.Errorf("unsupported message format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s message: %w", format, err)
+	}
+	return data, nil
+}
+
+// decodeJSON decodes a JSON object, the elements of a top-level array are stored under $items
+func decodeJSON(msg []byte) (map[string]interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal(msg, &value); err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case []interface{}:
+		return map[string]interface{}{common.MessageItemsKey: v}, nil
+	default:
+		return nil, fmt.Errorf("message must be an object or an array, got %T", value)
+	}
+}
+
+// decodeForm decodes a URL-encoded form, keys with multiple values are stored as arrays
+func decodeForm(msg []byte) (map[string]interface{}, error) {
+	values, err := url.ParseQuery(string(msg))
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{}, len(values))
+	for key, v := range values {
+		if len(v) == 1 {
+			data[key] = v[0]
+			continue
+		}
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		data[key] = items
+	}
+	return data, nil
+}
+
+// decodeText stores the message as it is under $body
+func decodeText(msg []byte) (map[string]interface{}, error) {
+	return map[string]interface{}{common.MessageBodyKey: string(msg)}, nil
+}
@@ -0,0 +1,126 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		msg      string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "json object",
+			msg:      `{"id": 1, "name": "John"}`,
+			expected: map[string]interface{}{"id": float64(1), "name": "John"},
+		},
+		{
+			name:   "json array",
+			format: "json",
+			msg:    `[{"id": 1}, {"id": 2}]`,
+			expected: map[string]interface{}{
+				"$items": []interface{}{map[string]interface{}{"id": float64(1)}, map[string]interface{}{"id": float64(2)}},
+			},
+		},
+		{
+			name:   "xml",
+			format: "xml",
+			msg: `<?xml version="1.0"?>
+<order id="42">
+  <customer>John</customer>
+  <item sku="a1">Book</item>
+  <item sku="b2">Pen</item>
+  <note/>
+</order>`,
+			expected: map[string]interface{}{
+				"@id":      "42",
+				"customer": "John",
+				"item": []interface{}{
+					map[string]interface{}{"@sku": "a1", "#text": "Book"},
+					map[string]interface{}{"@sku": "b2", "#text": "Pen"},
+				},
+				"note": "",
+			},
+		},
+		{
+			name:     "xml text root",
+			format:   "xml",
+			msg:      `<message>hello</message>`,
+			expected: map[string]interface{}{"#text": "hello"},
+		},
+		{
+			name:   "csv with a single row",
+			format: "csv",
+			msg:    "id,name\n1,John\n",
+			expected: map[string]interface{}{
+				"id":     "1",
+				"name":   "John",
+				"$items": []interface{}{map[string]interface{}{"id": "1", "name": "John"}},
+			},
+		},
+		{
+			name:   "csv with multiple rows",
+			format: "csv",
+			msg:    "id,name\r\n1,John\r\n2,\"Doe, Jane\"\r\n",
+			expected: map[string]interface{}{
+				"$items": []interface{}{
+					map[string]interface{}{"id": "1", "name": "John"},
+					map[string]interface{}{"id": "2", "name": "Doe, Jane"},
+				},
+			},
+		},
+		{
+			name:     "form",
+			format:   "form",
+			msg:      "name=John+Doe&tag=a&tag=b",
+			expected: map[string]interface{}{"name": "John Doe", "tag": []interface{}{"a", "b"}},
+		},
+		{
+			name:     "text",
+			format:   "text",
+			msg:      "plain message",
+			expected: map[string]interface{}{"$body": "plain message"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Decode(&config.QueueConfig{Format: tc.format}, []byte(tc.msg))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(data, tc.expected) {
+				t.Errorf("Decode() = %v, expected %v", data, tc.expected)
+			}
+		})
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		msg    string
+	}{
+		{name: "json scalar", format: "json", msg: `"text"`},
+		{name: "invalid json", format: "json", msg: `{"id":`},
+		{name: "unclosed xml", format: "xml", msg: `<order><id>1</id>`},
+		{name: "empty xml", format: "xml", msg: ``},
+		{name: "csv row with missing fields", format: "csv", msg: "id,name\n1\n"},
+		{name: "empty csv", format: "csv", msg: ``},
+		{name: "unsupported format", format: "yaml", msg: `id: 1`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Decode(&config.QueueConfig{Format: tc.format}, []byte(tc.msg)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/requester"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+// registrySchema is a schema returned by a Confluent compatible schema registry
+type registrySchema struct {
+	Schema     string              `json:"schema"`
+	SchemaType string              `json:"schemaType"`
+	References []registryReference `json:"references"`
+}
+
+// registryReference is a schema that a schema refers to, such as an imported .proto file
+type registryReference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// registryClient fetches schemas from a Confluent compatible schema registry
+type registryClient struct {
+	cfg    *config.SchemaRegistryConfig
+	client *http.Client
+}
+
+// newRegistryClient creates a client of the schema registry of the configuration
+func newRegistryClient(cfg *config.SchemaRegistryConfig) (*registryClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		tlsCfg, err := requester.NewTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+	return &registryClient{
+		cfg:    cfg,
+		client: &http.Client{Transport: transport, Timeout: cfg.Timeout},
+	}, nil
+}
+
+// schemaByID fetches the schema with the given ID
+func (c *registryClient) schemaByID(id int) (*registrySchema, error) {
+	return c.get(fmt.Sprintf("/schemas/ids/%d", id))
+}
+
+// schemaByVersion fetches the schema of a version of a subject, which is how the references of a schema are fetched
+func (c *registryClient) schemaByVersion(subject string, version int) (*registrySchema, error) {
+	return c.get(fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version))
+}
+
+func (c *registryClient) get(path string) (*registrySchema, error) {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(c.cfg.URL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json, application/json")
+	if c.cfg.Username != "" || c.cfg.Password != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.ReadRequestBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry responded to %s with status %d: %s", path, resp.StatusCode, body)
+	}
+
+	var schema registrySchema
+	if err = json.Unmarshal(body, &schema); err != nil {
+		return nil, fmt.Errorf("error parsing schema registry response: %w", err)
+	}
+	return &schema, nil
+}
@@ -0,0 +1,288 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	registrySchemaTypeAvro     = "AVRO"
+	registrySchemaTypeProtobuf = "PROTOBUF"
+)
+
+// protoJSON converts decoded protobuf messages into JSON with the field names of the .proto file,
+// fields with default values are included so that they can be mapped like any other field
+var protoJSON = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// schema is a parsed avro or protobuf schema
+type schema struct {
+	codec *goavro.Codec
+	file  protoreflect.FileDescriptor
+}
+
+// schemaDecoder decodes the avro or protobuf messages of a queue with a local schema or the schemas of a registry,
+// which are cached by their ID
+type schemaDecoder struct {
+	format   string
+	cfg      *config.SchemaConfig
+	registry *registryClient
+	local    *schema
+
+	mu      sync.Mutex
+	schemas map[int]*schema
+}
+
+var (
+	schemaDecodersMu sync.Mutex
+	schemaDecoders   = make(map[*config.QueueConfig]*schemaDecoder)
+)
+
+// LoadSchema prepares the decoding of the avro or protobuf messages of the queue, so that a local schema file
+// that can not be parsed fails at startup instead of for each message
+func LoadSchema(qCfg *config.QueueConfig) error {
+	_, err := schemaDecoderFor(qCfg)
+	return err
+}
+
+// schemaDecoderFor returns the schema decoder of the queue, creating it on first use
+func schemaDecoderFor(qCfg *config.QueueConfig) (*schemaDecoder, error) {
+	if qCfg.Schema == nil {
+		return nil, fmt.Errorf("schema of queue %s not defined", qCfg.Name)
+	}
+
+	schemaDecodersMu.Lock()
+	defer schemaDecodersMu.Unlock()
+
+	d, ok := schemaDecoders[qCfg]
+	if !ok {
+		var err error
+		d, err = newSchemaDecoder(qCfg.Format, qCfg.Schema)
+		if err != nil {
+			return nil, err
+		}
+		schemaDecoders[qCfg] = d
+	}
+	return d, nil
+}
+
+// newSchemaDecoder creates a schema decoder, a local schema file is parsed right away
+func newSchemaDecoder(format string, cfg *config.SchemaConfig) (*schemaDecoder, error) {
+	d := &schemaDecoder{format: format, cfg: cfg, schemas: make(map[int]*schema)}
+	var err error
+	if cfg.Registry != nil {
+		d.registry, err = newRegistryClient(cfg.Registry)
+		return d, err
+	}
+
+	switch format {
+	case common.MessageFormatAvro:
+		var source []byte
+		source, err = os.ReadFile(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		d.local, err = parseAvro(string(source))
+	case common.MessageFormatProtobuf:
+		importPaths := append([]string{filepath.Dir(cfg.File)}, cfg.ImportPaths...)
+		d.local, err = parseProto(filepath.Base(cfg.File), &protocompile.SourceResolver{ImportPaths: importPaths})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema file %s: %w", cfg.File, err)
+	}
+	return d, nil
+}
+
+// decode decodes a message with its schema. Messages decoded with a registry, or with a local file if the schema
+// is framed, start with the Confluent wire header
+func (d *schemaDecoder) decode(msg []byte) (map[string]interface{}, error) {
+	s := d.local
+	data := msg
+	var indexes []int
+	if d.registry != nil || d.cfg.Framed {
+		id, rest, err := splitWireHeader(msg)
+		if err != nil {
+			return nil, err
+		}
+		if d.registry != nil {
+			if s, err = d.schemaByID(id); err != nil {
+				return nil, err
+			}
+		}
+		data = rest
+		if d.format == common.MessageFormatProtobuf {
+			if indexes, data, err = splitMessageIndexes(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if d.format == common.MessageFormatAvro {
+		return decodeAvro(s.codec, data)
+	}
+	md, err := s.message(d.cfg.Message, indexes)
+	if err != nil {
+		return nil, err
+	}
+	return decodeProtobuf(md, data)
+}
+
+// schemaByID returns the schema with the given ID, fetching it from the registry if it is not cached.
+// A schema that can not be fetched is not cached, so that it is fetched again for the next message
+func (d *schemaDecoder) schemaByID(id int) (*schema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s, ok := d.schemas[id]; ok {
+		return s, nil
+	}
+
+	fetched, err := d.registry.schemaByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching schema %d: %w", id, err)
+	}
+	schemaType := fetched.SchemaType
+	if schemaType == "" {
+		schemaType = registrySchemaTypeAvro
+	}
+
+	var s *schema
+	switch {
+	case d.format == common.MessageFormatAvro && schemaType == registrySchemaTypeAvro:
+		if len(fetched.References) > 0 {
+			return nil, fmt.Errorf("schema %d: references of avro schemas are not supported", id)
+		}
+		s, err = parseAvro(fetched.Schema)
+	case d.format == common.MessageFormatProtobuf && schemaType == registrySchemaTypeProtobuf:
+		name := fmt.Sprintf("konsume-schema-%d.proto", id)
+		sources := map[string]string{name: fetched.Schema}
+		if err = d.fetchReferences(fetched.References, sources); err != nil {
+			return nil, fmt.Errorf("schema %d: %w", id, err)
+		}
+		s, err = parseProto(name, &protocompile.SourceResolver{Accessor: protocompile.SourceAccessorFromMap(sources)})
+	default:
+		return nil, fmt.Errorf("schema %d is a %s schema, not %s", id, schemaType, d.format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema %d: %w", id, err)
+	}
+	d.schemas[id] = s
+	return s, nil
+}
+
+// fetchReferences fetches the schemas that are referred to, such as the imports of a .proto file, by their names
+func (d *schemaDecoder) fetchReferences(references []registryReference, sources map[string]string) error {
+	for _, reference := range references {
+		if _, ok := sources[reference.Name]; ok {
+			continue
+		}
+		fetched, err := d.registry.schemaByVersion(reference.Subject, reference.Version)
+		if err != nil {
+			return fmt.Errorf("error fetching reference %s: %w", reference.Name, err)
+		}
+		sources[reference.Name] = fetched.Schema
+		if err = d.fetchReferences(fetched.References, sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseAvro parses an avro schema, unions are decoded into their values instead of objects keyed by their type
+func parseAvro(source string) (*schema, error) {
+	codec, err := goavro.NewCodecForStandardJSONFull(source)
+	if err != nil {
+		return nil, err
+	}
+	return &schema{codec: codec}, nil
+}
+
+// parseProto compiles a .proto file and its imports, the well-known types of protobuf can always be imported
+func parseProto(name string, resolver protocompile.Resolver) (*schema, error) {
+	compiler := protocompile.Compiler{Resolver: protocompile.WithStandardImports(resolver)}
+	files, err := compiler.Compile(context.Background(), name)
+	if err != nil {
+		return nil, err
+	}
+	return &schema{file: files[0]}, nil
+}
+
+// message returns the protobuf message type with the given full name, or the message type at the indexes of the
+// wire header if no name is given
+func (s *schema) message(name string, indexes []int) (protoreflect.MessageDescriptor, error) {
+	if name != "" {
+		if md := findMessage(s.file.Messages(), protoreflect.FullName(name)); md != nil {
+			return md, nil
+		}
+		if md := findMessage(s.file.Messages(), s.file.Package().Append(protoreflect.Name(name))); md != nil {
+			return md, nil
+		}
+		return nil, fmt.Errorf("message %s not found in schema", name)
+	}
+
+	if len(indexes) == 0 {
+		indexes = []int{0}
+	}
+	messages := s.file.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index < 0 || index >= messages.Len() {
+			return nil, errors.New("message indexes do not match the schema")
+		}
+		md = messages.Get(index)
+		messages = md.Messages()
+	}
+	return md, nil
+}
+
+// findMessage finds a message type by its full name in the messages and their nested messages
+func findMessage(messages protoreflect.MessageDescriptors, name protoreflect.FullName) protoreflect.MessageDescriptor {
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		if md.FullName() == name {
+			return md
+		}
+		if nested := findMessage(md.Messages(), name); nested != nil {
+			return nested
+		}
+	}
+	return nil
+}
+
+// decodeAvro decodes an avro record through its JSON form, so that its values have the same types as JSON messages
+func decodeAvro(codec *goavro.Codec, data []byte) (map[string]interface{}, error) {
+	native, _, err := codec.NativeFromBinary(data)
+	if err != nil {
+		return nil, err
+	}
+	textual, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(textual)
+}
+
+// decodeProtobuf decodes a protobuf message through its JSON form, so that its values have the same types as JSON messages
+func decodeProtobuf(md protoreflect.MessageDescriptor, data []byte) (map[string]interface{}, error) {
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	textual, err := protoJSON.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(textual)
+}
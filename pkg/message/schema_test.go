@@ -0,0 +1,259 @@
+package message
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const orderAvroSchema = `{
+  "type": "record",
+  "name": "Order",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "quantity", "type": "int"},
+    {"name": "note", "type": ["null", "string"], "default": null}
+  ]
+}`
+
+const customerProto = `syntax = "proto3";
+package shop;
+
+message Customer {
+  string name = 1;
+}
+`
+
+const orderProto = `syntax = "proto3";
+package shop;
+
+import "customer.proto";
+
+message Order {
+  string id = 1;
+  shop.Customer customer = 2;
+  repeated string tags = 3;
+  int32 quantity = 4;
+}
+
+message Refund {
+  string order_id = 1;
+}
+`
+
+// registryStandIn serves schemas like a Confluent schema registry and counts the requests
+func registryStandIn(t *testing.T, responses map[string]interface{}) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if user, password, _ := r.BasicAuth(); user != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func registryConfig(format, url string) *config.QueueConfig {
+	return &config.QueueConfig{
+		Name:   "test",
+		Format: format,
+		Schema: &config.SchemaConfig{
+			Registry: &config.SchemaRegistryConfig{URL: url, Username: "user", Password: "secret", Timeout: time.Second},
+		},
+	}
+}
+
+// frame prefixes data with the Confluent wire header of the schema ID
+func frame(id uint32, data ...[]byte) []byte {
+	msg := []byte{wireMagicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(msg[1:], id)
+	for _, d := range data {
+		msg = append(msg, d...)
+	}
+	return msg
+}
+
+func encodeAvroOrder(t *testing.T, native map[string]interface{}) []byte {
+	codec, err := goavro.NewCodec(orderAvroSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.BinaryFromNative(nil, native)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func encodeProtoOrder() []byte {
+	var customer []byte
+	customer = protowire.AppendTag(customer, 1, protowire.BytesType)
+	customer = protowire.AppendString(customer, "John")
+
+	var order []byte
+	order = protowire.AppendTag(order, 1, protowire.BytesType)
+	order = protowire.AppendString(order, "o-1")
+	order = protowire.AppendTag(order, 2, protowire.BytesType)
+	order = protowire.AppendBytes(order, customer)
+	order = protowire.AppendTag(order, 3, protowire.BytesType)
+	order = protowire.AppendString(order, "new")
+	order = protowire.AppendTag(order, 3, protowire.BytesType)
+	order = protowire.AppendString(order, "priority")
+	return order
+}
+
+func TestDecode_AvroRegistry(t *testing.T) {
+	server, requests := registryStandIn(t, map[string]interface{}{
+		"/schemas/ids/7": map[string]interface{}{"schema": orderAvroSchema},
+	})
+	qCfg := registryConfig("avro", server.URL)
+
+	msg := frame(7, encodeAvroOrder(t, map[string]interface{}{
+		"id":       "o-1",
+		"quantity": 3,
+		"note":     goavro.Union("string", "leave at the door"),
+	}))
+	expected := map[string]interface{}{"id": "o-1", "quantity": float64(3), "note": "leave at the door"}
+
+	for i := 0; i < 2; i++ {
+		data, err := Decode(qCfg, msg)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if !reflect.DeepEqual(data, expected) {
+			t.Errorf("Decode() = %v, expected %v", data, expected)
+		}
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("expected the schema to be fetched once, got %d requests", n)
+	}
+}
+
+func TestDecode_ProtobufRegistry(t *testing.T) {
+	server, _ := registryStandIn(t, map[string]interface{}{
+		"/schemas/ids/3": map[string]interface{}{
+			"schema":     orderProto,
+			"schemaType": "PROTOBUF",
+			"references": []map[string]interface{}{{"name": "customer.proto", "subject": "customer", "version": 2}},
+		},
+		"/subjects/customer/versions/2": map[string]interface{}{"schema": customerProto, "schemaType": "PROTOBUF"},
+	})
+	qCfg := registryConfig("protobuf", server.URL)
+
+	// a single 0 as message indexes stands for the first message of the schema
+	data, err := Decode(qCfg, frame(3, []byte{0}, encodeProtoOrder()))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	expected := map[string]interface{}{
+		"id":       "o-1",
+		"customer": map[string]interface{}{"name": "John"},
+		"tags":     []interface{}{"new", "priority"},
+		"quantity": float64(0),
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Decode() = %v, expected %v", data, expected)
+	}
+
+	// message indexes [1] select the second message of the schema
+	refund := protowire.AppendTag(nil, 1, protowire.BytesType)
+	refund = protowire.AppendString(refund, "o-1")
+	data, err = Decode(qCfg, frame(3, binary.AppendVarint(binary.AppendVarint(nil, 1), 1), refund))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"order_id": "o-1"}) {
+		t.Errorf("Decode() = %v, expected the refund message", data)
+	}
+}
+
+func TestDecode_RegistryErrors(t *testing.T) {
+	server, _ := registryStandIn(t, map[string]interface{}{
+		"/schemas/ids/1": map[string]interface{}{"schema": orderAvroSchema},
+	})
+
+	tests := []struct {
+		name   string
+		format string
+		msg    []byte
+	}{
+		{name: "missing wire header", format: "avro", msg: []byte(`{"id":"o-1"}`)},
+		{name: "unknown schema", format: "avro", msg: frame(2, []byte{0})},
+		{name: "schema of another format", format: "protobuf", msg: frame(1, []byte{0})},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Decode(registryConfig(tc.format, server.URL), tc.msg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestDecode_LocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"order.avsc":     orderAvroSchema,
+		"order.proto":    orderProto,
+		"customer.proto": customerProto,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	avroCfg := &config.QueueConfig{Name: "avro", Format: "avro", Schema: &config.SchemaConfig{File: filepath.Join(dir, "order.avsc")}}
+	if err := LoadSchema(avroCfg); err != nil {
+		t.Fatalf("LoadSchema() error = %v", err)
+	}
+	data, err := Decode(avroCfg, encodeAvroOrder(t, map[string]interface{}{"id": "o-1", "quantity": 3, "note": nil}))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"id": "o-1", "quantity": float64(3), "note": nil}) {
+		t.Errorf("Decode() = %v", data)
+	}
+
+	protoCfg := &config.QueueConfig{
+		Name:   "protobuf",
+		Format: "protobuf",
+		Schema: &config.SchemaConfig{File: filepath.Join(dir, "order.proto"), Message: "Order", Framed: true},
+	}
+	data, err = Decode(protoCfg, frame(9, []byte{0}, encodeProtoOrder()))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if data["id"] != "o-1" || !reflect.DeepEqual(data["customer"], map[string]interface{}{"name": "John"}) {
+		t.Errorf("Decode() = %v", data)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "invalid.proto"), []byte("message {"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidCfg := &config.QueueConfig{Name: "invalid", Format: "protobuf", Schema: &config.SchemaConfig{File: filepath.Join(dir, "invalid.proto")}}
+	if err = LoadSchema(invalidCfg); err == nil {
+		t.Error("expected an error for a schema file that can not be parsed")
+	}
+}
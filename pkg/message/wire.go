@@ -0,0 +1,46 @@
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// wireMagicByte is the first byte of a message in the Confluent wire format
+	wireMagicByte = 0
+
+	// wireHeaderSize is the size of the magic byte and the big endian schema ID
+	wireHeaderSize = 5
+)
+
+// splitWireHeader returns the schema ID of a message in the Confluent wire format and the data after the header
+func splitWireHeader(msg []byte) (int, []byte, error) {
+	if len(msg) < wireHeaderSize || msg[0] != wireMagicByte {
+		return 0, nil, errors.New("message does not start with the schema registry wire header")
+	}
+	return int(binary.BigEndian.Uint32(msg[1:wireHeaderSize])), msg[wireHeaderSize:], nil
+}
+
+// splitMessageIndexes returns the indexes of the protobuf message type that follow the wire header and the data after them.
+// The indexes are the path to the message type through the messages of the schema, a single 0 stands for the first message
+func splitMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 || count > int64(len(data)) {
+		return nil, nil, errors.New("invalid protobuf message indexes")
+	}
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, errors.New("invalid protobuf message indexes")
+		}
+		indexes[i] = int(index)
+		data = data[n:]
+	}
+	return indexes, data, nil
+}
@@ -0,0 +1,88 @@
+package message
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+)
+
+// decodeXML decodes the children of the root element of an XML document. Elements that only contain text become
+// strings, attributes are stored with an @ prefix, the text of elements with attributes or children under #text
+// and repeated elements become arrays
+func decodeXML(msg []byte) (map[string]interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(msg))
+	for {
+		token, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		value, err := decodeXMLElement(d, start)
+		if err != nil {
+			return nil, err
+		}
+		if data, ok := value.(map[string]interface{}); ok {
+			return data, nil
+		}
+		return map[string]interface{}{common.MessageTextKey: value}, nil
+	}
+}
+
+// decodeXMLElement decodes an element up to its end element
+func decodeXMLElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	data := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		data[common.XMLAttrPrefix+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+			addXMLChild(data, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(data) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				data[common.MessageTextKey] = content
+			}
+			return data, nil
+		}
+	}
+}
+
+// addXMLChild adds a child element, a repeated element is turned into an array
+func addXMLChild(data map[string]interface{}, name string, child interface{}) {
+	existing, ok := data[name]
+	if !ok {
+		data[name] = child
+		return
+	}
+	if items, ok := existing.([]interface{}); ok {
+		data[name] = append(items, child)
+		return
+	}
+	data[name] = []interface{}{existing, child}
+}
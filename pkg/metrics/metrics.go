@@ -19,6 +19,11 @@ var (
 		Help: "Total number of messages consumed",
 	})
 
+	MessagesDuplicated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "konsume_messages_duplicated_total",
+		Help: "Total number of messages skipped because they were already processed",
+	})
+
 	HttpRequestsMade = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "konsume_http_requests_made_total",
 		Help: "Total number of HTTP requests made",
@@ -33,15 +38,34 @@ var (
 		Name: "konsume_http_requests_failed_total",
 		Help: "Total number of HTTP requests failed",
 	})
+
+	CircuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "konsume_circuit_breaker_state",
+		Help: "State of the circuit breaker of a route, 0 is closed, 1 is half-open and 2 is open",
+	}, []string{"route"})
+
+	DatabaseInsertsSucceeded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konsume_database_inserts_succeeded_total",
+		Help: "Total number of database inserts succeeded",
+	}, []string{"route"})
+
+	DatabaseInsertsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "konsume_database_inserts_failed_total",
+		Help: "Total number of database inserts failed",
+	}, []string{"route"})
 )
 
 // InitMetrics initializes the metrics endpoint for prometheus with custom metrics
 func InitMetrics(cfg *config.MetricsConfig) {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(MessagesConsumed)
+	registry.MustRegister(MessagesDuplicated)
 	registry.MustRegister(HttpRequestsMade)
 	registry.MustRegister(HttpRequestsSucceeded)
 	registry.MustRegister(HttpRequestsFailed)
+	registry.MustRegister(CircuitBreakerState)
+	registry.MustRegister(DatabaseInsertsSucceeded)
+	registry.MustRegister(DatabaseInsertsFailed)
 	registry.MustRegister(collectors.NewBuildInfoCollector())
 	registry.MustRegister(collectors.NewGoCollector())
 
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/queue"
 
@@ -16,6 +17,8 @@ type Consumer struct {
 	conn   *stomp.Conn
 	sub    *stomp.Subscription
 	config *config.StompConfig
+
+	concurrency int
 }
 
 func NewConsumer(cfg *config.StompConfig) *Consumer {
@@ -47,26 +50,74 @@ func (c *Consumer) Connect() error {
 	return nil
 }
 
+// Consume consumes messages from ActiveMQ
 func (c *Consumer) Consume(queueName string, handler func(msg []byte) error) error {
+	return c.ConsumeWithMetadata(queueName, func(msg []byte, _ map[string]string) error {
+		return handler(msg)
+	})
+}
+
+// ConsumeWithMetadata consumes messages from ActiveMQ and passes the headers of each frame to the handler,
+// the message ID is the message-id header set by the broker
+func (c *Consumer) ConsumeWithMetadata(queueName string, handler func(msg []byte, metadata map[string]string) error) error {
 	slog.Debug("Starting to consume messages from ActiveMQ", "queueName", queueName)
 	sub, err := c.conn.Subscribe(queueName, stomp.AckAuto)
 	if err != nil {
 		return err
 	}
 	go func() {
+		var sem chan struct{}
+		if c.concurrency > 1 {
+			sem = make(chan struct{}, c.concurrency)
+		}
 		for {
 			m, err := sub.Read()
 			if err != nil {
 				slog.Error("Failed to read message from ActiveMQ", "error", err)
 			}
-			if err = handler(m.Body); err != nil {
-				slog.Error("Failed to process message", "error", err)
+			metadata := frameMetadata(m)
+			if sem == nil {
+				handleMessage(m.Body, metadata, handler)
+				continue
 			}
+			sem <- struct{}{}
+			go func(body []byte) {
+				defer func() { <-sem }()
+				handleMessage(body, metadata, handler)
+			}(m.Body)
 		}
 	}()
 	return nil
 }
 
+// SetConcurrency sets the amount of messages that are handled at the same time
+func (c *Consumer) SetConcurrency(n int) {
+	c.concurrency = n
+}
+
+// frameMetadata returns the message ID, destination and headers of the message
+func frameMetadata(m *stomp.Message) map[string]string {
+	metadata := map[string]string{"destination": m.Destination}
+	if m.Header == nil {
+		return metadata
+	}
+	for i := 0; i < m.Header.Len(); i++ {
+		key, value := m.Header.GetAt(i)
+		metadata[common.MetadataHeaderPrefix+key] = value
+	}
+	if id := m.Header.Get("message-id"); id != "" {
+		metadata[common.MetadataMessageID] = id
+	}
+	return metadata
+}
+
+// handleMessage runs the handler for the message and logs the error if it fails
+func handleMessage(body []byte, metadata map[string]string, handler func(msg []byte, metadata map[string]string) error) {
+	if err := handler(body, metadata); err != nil {
+		slog.Error("Failed to process message", "error", err)
+	}
+}
+
 func (c *Consumer) Close() error {
 	slog.Debug("Closing connection to ActiveMQ")
 	err := c.sub.Unsubscribe()
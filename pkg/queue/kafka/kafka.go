@@ -3,7 +3,9 @@ package kafka
 import (
 	"context"
 	"log/slog"
+	"strconv"
 
+	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/queue"
 
@@ -14,6 +16,8 @@ import (
 type Consumer struct {
 	config *config.KafkaConfig
 	conn   *kafka.Conn
+
+	concurrency int
 }
 
 // NewConsumer creates a new Kafka consumer
@@ -42,16 +46,66 @@ func (c *Consumer) Connect() error {
 
 // Consume consumes messages from Kafka
 func (c *Consumer) Consume(queueName string, handler func(msg []byte) error) error {
+	return c.ConsumeWithMetadata(queueName, func(msg []byte, _ map[string]string) error {
+		return handler(msg)
+	})
+}
+
+// ConsumeWithMetadata consumes messages from Kafka and passes the key, partition, offset and headers of each message
+// to the handler. The message ID is the topic, partition and offset of the message, such as "orders/0/42"
+func (c *Consumer) ConsumeWithMetadata(queueName string, handler func(msg []byte, metadata map[string]string) error) error {
 	slog.Debug("Starting to consume messages from Kafka", "topic", queueName)
+	var sem chan struct{}
+	if c.concurrency > 1 {
+		sem = make(chan struct{}, c.concurrency)
+	}
 	for {
 		msg, err := c.conn.ReadMessage(10e6)
 		if err != nil {
 			slog.Error("Failed to read message from Kafka", "error", err)
 			return err
 		}
-		if err = handler(msg.Value); err != nil {
-			slog.Error("Failed to process message", "error", err)
+		metadata := c.messageMetadata(msg)
+		if sem == nil {
+			handleMessage(msg.Value, metadata, handler)
+			continue
 		}
+		sem <- struct{}{}
+		go func(value []byte) {
+			defer func() { <-sem }()
+			handleMessage(value, metadata, handler)
+		}(msg.Value)
+	}
+}
+
+// SetConcurrency sets the amount of messages that are handled at the same time
+func (c *Consumer) SetConcurrency(n int) {
+	c.concurrency = n
+}
+
+// messageMetadata returns the topic, partition, offset, key and headers of the message
+func (c *Consumer) messageMetadata(msg kafka.Message) map[string]string {
+	partition := strconv.Itoa(msg.Partition)
+	offset := strconv.FormatInt(msg.Offset, 10)
+	metadata := map[string]string{
+		common.MetadataMessageID: c.config.Topic + "/" + partition + "/" + offset,
+		"topic":                  c.config.Topic,
+		"partition":              partition,
+		"offset":                 offset,
+	}
+	if msg.Key != nil {
+		metadata["key"] = string(msg.Key)
+	}
+	for _, header := range msg.Headers {
+		metadata[common.MetadataHeaderPrefix+header.Key] = string(header.Value)
+	}
+	return metadata
+}
+
+// handleMessage runs the handler for the message and logs the error if it fails
+func handleMessage(value []byte, metadata map[string]string, handler func(msg []byte, metadata map[string]string) error) {
+	if err := handler(value, metadata); err != nil {
+		slog.Error("Failed to process message", "error", err)
 	}
 }
 
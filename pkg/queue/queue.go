@@ -6,3 +6,16 @@ type MessageQueueConsumer interface {
 	Consume(queueName string, handler func(msg []byte) error) error
 	Close() error
 }
+
+// ConcurrentConsumer is the interface that consumers implement when they can handle multiple messages at the same time.
+// Each message is still acknowledged after its own handler returns, consumers without acknowledgements such as Kafka
+// only log the failed messages
+type ConcurrentConsumer interface {
+	SetConcurrency(n int)
+}
+
+// MetadataConsumer is the interface that consumers implement when they can pass the delivery metadata of each message
+// to the handler, such as the message ID set by the broker under "message-id" and the headers under "header.<name>"
+type MetadataConsumer interface {
+	ConsumeWithMetadata(queueName string, handler func(msg []byte, metadata map[string]string) error) error
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/queue"
 
@@ -15,6 +16,8 @@ type Consumer struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	config  *config.AMQPConfig
+
+	concurrency int
 }
 
 // NewConsumer creates a new RabbitMQ consumer
@@ -50,7 +53,19 @@ func (c *Consumer) Connect() error {
 
 // Consume consumes messages from RabbitMQ
 func (c *Consumer) Consume(queueName string, handler func(msg []byte) error) error {
+	return c.ConsumeWithMetadata(queueName, func(msg []byte, _ map[string]string) error {
+		return handler(msg)
+	})
+}
+
+// ConsumeWithMetadata consumes messages from RabbitMQ and passes the properties and headers of each delivery to the handler
+func (c *Consumer) ConsumeWithMetadata(queueName string, handler func(msg []byte, metadata map[string]string) error) error {
 	slog.Debug("Starting to consume messages from RabbitMQ", "queueName", queueName)
+	if c.concurrency > 1 {
+		if err := c.channel.Qos(c.concurrency, 0, false); err != nil {
+			return fmt.Errorf("error setting prefetch count: %w", err)
+		}
+	}
 	msgs, err := c.channel.Consume(
 		queueName,
 		"konsume", // Consumer tag - Identifier for the consumer
@@ -65,26 +80,65 @@ func (c *Consumer) Consume(queueName string, handler func(msg []byte) error) err
 	}
 
 	go func() {
-		for d := range msgs {
-			err = handler(d.Body)
-			if err != nil {
-				slog.Error("Failed to process message sending to dead letter exchange", "message", string(d.Body), "error", err)
-				err = d.Nack(false, false)
-				if err != nil {
-					slog.Error("Failed to nack the message", "error", err)
-				}
-			} else {
-				err = d.Ack(false)
-				if err != nil {
-					slog.Error("Failed to ack the message", "error", err)
-				}
+		if c.concurrency <= 1 {
+			for d := range msgs {
+				handleDelivery(d, handler)
 			}
+			return
+		}
+		sem := make(chan struct{}, c.concurrency)
+		for d := range msgs {
+			sem <- struct{}{}
+			go func(d amqp.Delivery) {
+				defer func() { <-sem }()
+				handleDelivery(d, handler)
+			}(d)
 		}
 	}()
 
 	return nil
 }
 
+// SetConcurrency sets the amount of messages that are handled at the same time
+func (c *Consumer) SetConcurrency(n int) {
+	c.concurrency = n
+}
+
+// handleDelivery runs the handler for the delivery and acknowledges it, or sends it to the dead letter exchange if the handler fails
+func handleDelivery(d amqp.Delivery, handler func(msg []byte, metadata map[string]string) error) {
+	err := handler(d.Body, deliveryMetadata(d))
+	if err != nil {
+		slog.Error("Failed to process message sending to dead letter exchange", "message", string(d.Body), "error", err)
+		err = d.Nack(false, false)
+		if err != nil {
+			slog.Error("Failed to nack the message", "error", err)
+		}
+	} else {
+		err = d.Ack(false)
+		if err != nil {
+			slog.Error("Failed to ack the message", "error", err)
+		}
+	}
+}
+
+// deliveryMetadata returns the message ID, correlation ID, exchange, routing key and headers of the delivery
+func deliveryMetadata(d amqp.Delivery) map[string]string {
+	metadata := map[string]string{
+		"exchange":    d.Exchange,
+		"routing-key": d.RoutingKey,
+	}
+	if d.MessageId != "" {
+		metadata[common.MetadataMessageID] = d.MessageId
+	}
+	if d.CorrelationId != "" {
+		metadata["correlation-id"] = d.CorrelationId
+	}
+	for key, value := range d.Headers {
+		metadata[common.MetadataHeaderPrefix+key] = fmt.Sprint(value)
+	}
+	return metadata
+}
+
 // Close closes the connection to RabbitMQ
 func (c *Consumer) Close() error {
 	slog.Debug("Closing RabbitMQ connection and channel")
@@ -24,6 +24,10 @@ type Requester struct {
 	Method   string
 	Body     []byte
 	Headers  map[string]string
+	Signing  *config.SigningConfig
+
+	// Transport is the transport used to send the request, the default transport is used when it is nil
+	Transport http.RoundTripper
 }
 
 // NewRequester creates a new Requester struct.
@@ -58,9 +62,16 @@ func (r *Requester) SendRequest(m *config.MetricsConfig, timeout time.Duration)
 			req.Header.Add(k, v)
 		}
 	}
+	if r.Signing != nil {
+		if err = signRequest(req, r.Body, r.Signing); err != nil {
+			slog.Error("Failed to sign request", "error", err)
+			return nil, err
+		}
+	}
 
 	client := &http.Client{
-		Timeout: timeout,
+		Timeout:   timeout,
+		Transport: r.Transport,
 	}
 
 	resp, err = client.Do(req)
@@ -0,0 +1,164 @@
+package requester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+const (
+	awsAlgorithm     = "AWS4-HMAC-SHA256"
+	awsDateFormat    = "20060102"
+	awsAmzDateFormat = "20060102T150405Z"
+)
+
+// now returns the current time, it is a variable so that tests can freeze the clock
+var now = time.Now
+
+// signRequest signs the request with the given signing configuration
+func signRequest(req *http.Request, body []byte, cfg *config.SigningConfig) error {
+	switch cfg.Scheme {
+	case common.SigningSchemeDigest:
+		timestamp := strconv.FormatInt(now().Unix(), 10)
+		req.Header.Set(cfg.TimestampHeader, timestamp)
+		req.Header.Set(cfg.Header, computeHMAC(cfg, timestamp, body))
+	case common.SigningSchemeStripe:
+		timestamp := strconv.FormatInt(now().Unix(), 10)
+		req.Header.Set(cfg.Header, fmt.Sprintf("t=%s,v1=%s", timestamp, computeHMAC(cfg, timestamp, body)))
+	case common.SigningSchemeAWSSigV4:
+		signAWSv4(req, body, cfg.AWS, now().UTC())
+	default:
+		return fmt.Errorf("unsupported signing scheme: %s", cfg.Scheme)
+	}
+	return nil
+}
+
+// computeHMAC returns the hex encoded HMAC of the timestamp and the body joined with a dot
+func computeHMAC(cfg *config.SigningConfig, timestamp string, body []byte) string {
+	var hashFunc func() hash.Hash
+	if cfg.Algorithm == common.SigningAlgorithmSHA512 {
+		hashFunc = sha512.New
+	} else {
+		hashFunc = sha256.New
+	}
+	mac := hmac.New(hashFunc, []byte(cfg.Secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signAWSv4 signs the request with AWS Signature Version 4 and sets the Authorization header
+func signAWSv4(req *http.Request, body []byte, cfg *config.AWSSigningConfig, t time.Time) {
+	amzDate := t.Format(awsAmzDateFormat)
+	date := t.Format(awsDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalAWSHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalAWSPath(req.URL),
+		canonicalAWSQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		hashSHA256(body),
+	}, "\n")
+
+	scope := strings.Join([]string{date, cfg.Region, cfg.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{awsAlgorithm, amzDate, scope, hashSHA256([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, cfg.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsAlgorithm, cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalAWSHeaders returns the signed header list and the canonical header block, which covers host and x-amz-* headers
+func canonicalAWSHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" {
+			headers[name] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalAWSPath returns the URI encoded path of the url
+func canonicalAWSPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalAWSQuery returns the query parameters sorted by key and value and encoded as AWS expects
+func canonicalAWSQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, awsURIEncode(k)+"="+awsURIEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte except the unreserved characters of RFC 3986
+func awsURIEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hashSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,94 @@
+package requester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestSignRequest_Digest(t *testing.T) {
+	now = func() time.Time { return time.Unix(1700000000, 0) }
+	defer func() { now = time.Now }()
+
+	body := []byte(`{"key":"value"}`)
+	req, _ := http.NewRequest("POST", "http://localhost/test", nil)
+	cfg := &config.SigningConfig{
+		Scheme:          common.SigningSchemeDigest,
+		Algorithm:       common.SigningAlgorithmSHA256,
+		Secret:          "secret",
+		Header:          "X-Signature",
+		TimestampHeader: "X-Timestamp",
+	}
+	if err := signRequest(req, body, cfg); err != nil {
+		t.Fatalf("signRequest() error = %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	if req.Header.Get("X-Timestamp") != "1700000000" {
+		t.Errorf("expected timestamp header 1700000000, got %s", req.Header.Get("X-Timestamp"))
+	}
+	if req.Header.Get("X-Signature") != expected {
+		t.Errorf("expected signature %s, got %s", expected, req.Header.Get("X-Signature"))
+	}
+}
+
+func TestSignRequest_Stripe(t *testing.T) {
+	now = func() time.Time { return time.Unix(1700000000, 0) }
+	defer func() { now = time.Now }()
+
+	body := []byte(`{"key":"value"}`)
+	req, _ := http.NewRequest("POST", "http://localhost/test", nil)
+	cfg := &config.SigningConfig{
+		Scheme:    common.SigningSchemeStripe,
+		Algorithm: common.SigningAlgorithmSHA512,
+		Secret:    "secret",
+		Header:    "Stripe-Signature",
+	}
+	if err := signRequest(req, body, cfg); err != nil {
+		t.Fatalf("signRequest() error = %v", err)
+	}
+
+	expected := "t=1700000000,v1=" + computeHMAC(cfg, "1700000000", body)
+	if req.Header.Get("Stripe-Signature") != expected {
+		t.Errorf("expected signature %s, got %s", expected, req.Header.Get("Stripe-Signature"))
+	}
+	if len(computeHMAC(cfg, "1700000000", body)) != 128 {
+		t.Errorf("expected sha512 digest to be 128 hex characters")
+	}
+}
+
+func TestSignAWSv4(t *testing.T) {
+	// get-vanilla case of the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	cfg := &config.AWSSigningConfig{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+	}
+	signAWSv4(req, nil, cfg, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if req.Header.Get("Authorization") != expected {
+		t.Errorf("expected authorization %s, got %s", expected, req.Header.Get("Authorization"))
+	}
+}
+
+func TestCanonicalAWSQuery(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/?b=2&a=2&a=1&c=x%20y", nil)
+	got := canonicalAWSQuery(req.URL.Query())
+	if got != "a=1&a=2&b=2&c=x%20y" {
+		t.Errorf("unexpected canonical query: %s", got)
+	}
+}
@@ -0,0 +1,169 @@
+package requester
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certReloader keeps the client certificate and the CA pool of a route and reloads them when the files change on disk
+type certReloader struct {
+	cfg *config.TLSConfig
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	roots   *x509.CertPool
+	caMod   time.Time
+}
+
+// NewTLSConfig creates a tls.Config from the TLS configuration of a route or database
+func NewTLSConfig(cfg *config.TLSConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         cfg.ServerName,
+		MinVersion:         tlsVersions[cfg.MinVersion],
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	reloader := &certReloader{cfg: cfg}
+
+	if cfg.CertFile != "" {
+		if _, err := reloader.clientCertificate(); err != nil {
+			return nil, err
+		}
+		tlsCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return reloader.clientCertificate()
+		}
+	}
+
+	if cfg.CAFile != "" && !cfg.InsecureSkipVerify {
+		if _, err := reloader.rootCAs(); err != nil {
+			return nil, err
+		}
+		// Verification is done in VerifyConnection so that a rotated CA bundle is picked up without recreating the client
+		tlsCfg.InsecureSkipVerify = true
+		tlsCfg.VerifyConnection = reloader.verifyConnection
+	}
+
+	return tlsCfg, nil
+}
+
+// clientCertificate returns the client certificate, reloading it if the certificate file has been modified
+func (r *certReloader) clientCertificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		if r.cert != nil {
+			slog.Warn("Failed to stat client certificate, using the loaded one", "error", err)
+			return r.cert, nil
+		}
+		return nil, err
+	}
+	if r.cert != nil && !modTime.After(r.certMod) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		if r.cert != nil {
+			slog.Warn("Failed to reload client certificate, using the loaded one", "error", err)
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("error loading client certificate: %w", err)
+	}
+	if r.cert != nil {
+		slog.Info("Reloaded client certificate", "file", r.cfg.CertFile)
+	}
+	r.cert = &cert
+	r.certMod = modTime
+	return r.cert, nil
+}
+
+// rootCAs returns the CA pool, reloading it if the CA file has been modified
+func (r *certReloader) rootCAs() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.cfg.CAFile)
+	if err != nil {
+		if r.roots != nil {
+			slog.Warn("Failed to stat CA file, using the loaded one", "error", err)
+			return r.roots, nil
+		}
+		return nil, err
+	}
+	if r.roots != nil && !modTime.After(r.caMod) {
+		return r.roots, nil
+	}
+
+	pem, err := os.ReadFile(r.cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		if r.roots != nil {
+			slog.Warn("Failed to reload CA file, using the loaded one", "file", r.cfg.CAFile)
+			return r.roots, nil
+		}
+		return nil, errors.New("no certificates found in CA file " + r.cfg.CAFile)
+	}
+	if r.roots != nil {
+		slog.Info("Reloaded CA file", "file", r.cfg.CAFile)
+	}
+	r.roots = pool
+	r.caMod = modTime
+	return r.roots, nil
+}
+
+// verifyConnection verifies the server certificate chain against the current CA pool
+func (r *certReloader) verifyConnection(cs tls.ConnectionState) error {
+	roots, err := r.rootCAs()
+	if err != nil {
+		return err
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// latestModTime returns the most recent modification time of the given files
+func latestModTime(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
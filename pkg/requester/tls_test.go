@@ -0,0 +1,176 @@
+package requester
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	parentCert, parentKey := tmpl, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTransportFor_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil, true)
+	server := newTestCert(t, "localhost", ca, false)
+	client := newTestCert(t, "client", ca, false)
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca.cert)
+	serverCert, _ := tls.X509KeyPair(server.certPEM, server.keyPEM)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    caPool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	writeFile(t, filepath.Join(dir, "ca.pem"), ca.certPEM)
+	writeFile(t, filepath.Join(dir, "client.pem"), client.certPEM)
+	writeFile(t, filepath.Join(dir, "client.key"), client.keyPEM)
+
+	rCfg := &config.RouteConfig{
+		Name: "tls-route",
+		TLS: &config.TLSConfig{
+			CAFile:     filepath.Join(dir, "ca.pem"),
+			CertFile:   filepath.Join(dir, "client.pem"),
+			KeyFile:    filepath.Join(dir, "client.key"),
+			MinVersion: "1.2",
+		},
+	}
+	transport, err := TransportFor(rCfg)
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+	// every request makes a new connection, so the handshake after the rotation can not reuse the old certificate
+	transport.(*http.Transport).DisableKeepAlives = true
+
+	r := NewRequester(srv.URL, "GET", nil, nil)
+	r.Transport = transport
+	resp, err := r.SendRequest(nil, time.Second)
+	if err != nil {
+		t.Fatalf("SendRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	// rotate the client certificate on disk and make sure the new one is presented
+	rotated := newTestCert(t, "rotated-client", ca, false)
+	future := time.Now().Add(time.Minute)
+	writeFile(t, filepath.Join(dir, "client.pem"), rotated.certPEM)
+	writeFile(t, filepath.Join(dir, "client.key"), rotated.keyPEM)
+	for _, file := range []string{"client.pem", "client.key"} {
+		if err = os.Chtimes(filepath.Join(dir, file), future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err = r.SendRequest(nil, time.Second)
+	if err != nil {
+		t.Fatalf("SendRequest() after rotation error = %v", err)
+	}
+	body, err := util.ReadRequestBody(resp)
+	if err != nil {
+		t.Fatalf("error reading response body: %v", err)
+	}
+	if string(body) != "rotated-client" {
+		t.Errorf("expected rotated client certificate, server saw %s", body)
+	}
+}
+
+func TestTransportFor_UnknownCA(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	otherCA := newTestCert(t, "other-ca", nil, true)
+	writeFile(t, filepath.Join(dir, "ca.pem"), otherCA.certPEM)
+
+	transport, err := TransportFor(&config.RouteConfig{
+		TLS: &config.TLSConfig{CAFile: filepath.Join(dir, "ca.pem"), MinVersion: "1.2"},
+	})
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+	r := NewRequester(srv.URL, "GET", nil, nil)
+	r.Transport = transport
+	if _, err = r.SendRequest(nil, time.Second); err == nil {
+		t.Error("expected an error for a server certificate signed by an unknown CA")
+	}
+}
+
+func TestTransportFor_NoTLSOrProxy(t *testing.T) {
+	transport, err := TransportFor(&config.RouteConfig{})
+	if err != nil || transport != nil {
+		t.Errorf("expected nil transport and no error, got %v, %v", transport, err)
+	}
+}
@@ -0,0 +1,126 @@
+package requester
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+const defaultExpectContinueTimeout = time.Second
+
+var (
+	transportsMu sync.Mutex
+	transports   = make(map[interface{}]*http.Transport)
+)
+
+// TransportFor returns the pooled transport of the given route, creating it on first use.
+// Routes that share the same connection pool configuration and have no TLS or proxy settings share the same transport.
+// A nil transport means that the default transport should be used.
+func TransportFor(rCfg *config.RouteConfig) (http.RoundTripper, error) {
+	if rCfg.HTTP == nil && rCfg.TLS == nil && rCfg.Proxy == "" {
+		return nil, nil
+	}
+
+	var key interface{} = rCfg
+	if rCfg.TLS == nil && rCfg.Proxy == "" {
+		key = newPoolKey(rCfg.HTTP)
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+
+	if transport, ok := transports[key]; ok {
+		return transport, nil
+	}
+	transport, err := newTransport(rCfg)
+	if err != nil {
+		return nil, err
+	}
+	transports[key] = transport
+	return transport, nil
+}
+
+// poolKey identifies the connection pool configuration of the routes that share a transport,
+// routes share a transport when their configurations are equal even if they are not the same value
+type poolKey struct {
+	config.HTTPClientConfig
+	disableHTTP2      bool
+	disableKeepAlives bool
+}
+
+func newPoolKey(cfg *config.HTTPClientConfig) poolKey {
+	key := poolKey{HTTPClientConfig: *cfg, disableHTTP2: cfg.HTTP2Disabled(), disableKeepAlives: cfg.KeepAlivesDisabled()}
+	key.DisableHTTP2, key.DisableKeepAlives = nil, nil
+	return key
+}
+
+// CloseIdleConnections closes the idle connections of all pooled transports
+func CloseIdleConnections() {
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+
+	for _, transport := range transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// newTransport creates a transport with the connection pool, TLS and proxy settings of the route.
+// Routes without a connection pool configuration use the settings of the Go default transport
+func newTransport(rCfg *config.RouteConfig) (*http.Transport, error) {
+	var transport *http.Transport
+	if rCfg.HTTP == nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	} else {
+		transport = pooledTransport(rCfg.HTTP)
+	}
+
+	if rCfg.Proxy != "" {
+		proxyURL, err := url.Parse(rCfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if rCfg.TLS != nil {
+		tlsCfg, err := NewTLSConfig(rCfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+
+	return transport, nil
+}
+
+// pooledTransport creates a transport with the connection pool configuration
+func pooledTransport(httpCfg *config.HTTPClientConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   httpCfg.DialTimeout,
+		KeepAlive: httpCfg.KeepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !httpCfg.HTTP2Disabled(),
+		MaxIdleConns:          httpCfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   httpCfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       httpCfg.MaxConnsPerHost,
+		IdleConnTimeout:       httpCfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   httpCfg.TLSHandshakeTimeout,
+		DisableKeepAlives:     httpCfg.KeepAlivesDisabled(),
+		ExpectContinueTimeout: defaultExpectContinueTimeout,
+	}
+	if httpCfg.HTTP2Disabled() {
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	if httpCfg.DNSCacheTTL > 0 {
+		transport.DialContext = newDNSCache(httpCfg.DNSCacheTTL).dialContext(dialer)
+	}
+	return transport
+}
@@ -0,0 +1,112 @@
+package requester
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+func TestTransportFor_SharedPool(t *testing.T) {
+	disabled := true
+	shared := &config.HTTPClientConfig{MaxIdleConnsPerHost: 50, MaxConnsPerHost: 20, DisableHTTP2: &disabled}
+	copied := *shared
+	route1 := &config.RouteConfig{Name: "route1", HTTP: shared}
+	route2 := &config.RouteConfig{Name: "route2", HTTP: &copied}
+	route3 := &config.RouteConfig{Name: "route3", HTTP: shared, Proxy: "http://localhost:3128"}
+
+	t1, err := TransportFor(route1)
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+	t2, _ := TransportFor(route2)
+	t3, _ := TransportFor(route3)
+
+	if t1 != t2 {
+		t.Error("expected routes with the same pool configuration to share the transport")
+	}
+	if t1 == t3 {
+		t.Error("expected route with proxy settings to have its own transport")
+	}
+
+	transport := t1.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != 50 || transport.MaxConnsPerHost != 20 {
+		t.Errorf("unexpected pool limits: %d, %d", transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("expected HTTP/2 to be disabled")
+	}
+}
+
+func TestTransportFor_DefaultTransport(t *testing.T) {
+	transport, err := TransportFor(&config.RouteConfig{Name: "route", Proxy: "http://localhost:3128"})
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+	defaults := http.DefaultTransport.(*http.Transport)
+	tr := transport.(*http.Transport)
+	if tr.TLSHandshakeTimeout != defaults.TLSHandshakeTimeout || tr.IdleConnTimeout != defaults.IdleConnTimeout ||
+		tr.MaxIdleConns != defaults.MaxIdleConns || !tr.ForceAttemptHTTP2 {
+		t.Error("expected a route without connection pool configuration to use the settings of the default transport")
+	}
+}
+
+func TestTransportFor_ReusesConnections(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	srv.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	transport, err := TransportFor(&config.RouteConfig{HTTP: &config.HTTPClientConfig{MaxIdleConnsPerHost: 10}})
+	if err != nil {
+		t.Fatalf("TransportFor() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		r := NewRequester(srv.URL, "GET", nil, nil)
+		r.Transport = transport
+		resp, err := r.SendRequest(nil, time.Second)
+		if err != nil {
+			t.Fatalf("SendRequest() error = %v", err)
+		}
+		if _, err = util.ReadRequestBody(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("expected a single connection to be reused, got %d", n)
+	}
+}
+
+func TestDNSCache_Lookup(t *testing.T) {
+	cache := newDNSCache(time.Minute)
+	cache.entries["cached.konsume"] = dnsEntry{addrs: []string{"127.0.0.1"}, expires: time.Now().Add(time.Minute)}
+
+	addrs, err := cache.lookup(context.Background(), "cached.konsume")
+	if err != nil || len(addrs) != 1 || addrs[0] != "127.0.0.1" {
+		t.Errorf("expected cached address, got %v, %v", addrs, err)
+	}
+
+	cache.entries["localhost"] = dnsEntry{addrs: []string{"10.0.0.1"}, expires: time.Now().Add(-time.Second)}
+	addrs, err = cache.lookup(context.Background(), "localhost")
+	if err != nil {
+		t.Fatalf("lookup() error = %v", err)
+	}
+	for _, addr := range addrs {
+		if addr == "10.0.0.1" {
+			t.Error("expected expired entry to be resolved again")
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+)
+
+// batcher collects the items of concurrently handled messages and writes them together
+// when the batch is full or the wait duration has elapsed
+type batcher struct {
+	size  int
+	wait  time.Duration
+	write func(items []interface{}) error
+
+	mu         sync.Mutex
+	pending    []*batchItem
+	generation int
+	timer      *time.Timer
+}
+
+// batchItem is an item waiting in a batch and the channel its write result is sent to
+type batchItem struct {
+	value interface{}
+	done  chan error
+}
+
+var (
+	batchersMu sync.Mutex
+	batchers   = make(map[interface{}]*batcher)
+)
+
+func newBatcher(cfg *config.BatchConfig, write func(items []interface{}) error) *batcher {
+	return &batcher{size: cfg.Size, wait: cfg.Wait, write: write}
+}
+
+// batcherFor returns the batcher registered for the key, creating it with the given write function on first use
+func batcherFor(key interface{}, cfg *config.BatchConfig, write func(items []interface{}) error) *batcher {
+	batchersMu.Lock()
+	defer batchersMu.Unlock()
+
+	b, ok := batchers[key]
+	if !ok {
+		b = newBatcher(cfg, write)
+		batchers[key] = b
+	}
+	return b
+}
+
+// flushBatchers writes the pending items of all batchers
+func flushBatchers() {
+	batchersMu.Lock()
+	pending := make([]*batcher, 0, len(batchers))
+	for key, b := range batchers {
+		pending = append(pending, b)
+		delete(batchers, key)
+	}
+	batchersMu.Unlock()
+
+	for _, b := range pending {
+		b.flush(-1)
+	}
+}
+
+// add adds the value to the batch and blocks until the batch containing it has been written
+func (b *batcher) add(value interface{}) error {
+	item := &batchItem{value: value, done: make(chan error, 1)}
+
+	b.mu.Lock()
+	b.pending = append(b.pending, item)
+	var items []*batchItem
+	if len(b.pending) >= b.size {
+		items = b.take()
+	} else if len(b.pending) == 1 {
+		generation := b.generation
+		b.timer = time.AfterFunc(b.wait, func() { b.flush(generation) })
+	}
+	b.mu.Unlock()
+
+	if items != nil {
+		b.run(items)
+	}
+	return <-item.done
+}
+
+// flush writes the pending items if they belong to the given generation, or regardless of it if generation is negative
+func (b *batcher) flush(generation int) {
+	b.mu.Lock()
+	if generation >= 0 && generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+	items := b.take()
+	b.mu.Unlock()
+
+	if len(items) > 0 {
+		b.run(items)
+	}
+}
+
+// take removes the pending items and starts a new generation, the caller must hold the lock
+func (b *batcher) take() []*batchItem {
+	items := b.pending
+	b.pending = nil
+	b.generation++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return items
+}
+
+// run writes the items and sends the result to every message waiting for them,
+// a partially failed batch only fails the messages whose items could not be written
+func (b *batcher) run(items []*batchItem) {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = item.value
+	}
+	err := b.write(values)
+	var batchErr *database.BatchError
+	if errors.As(err, &batchErr) && len(batchErr.Errors) == len(items) {
+		for i, item := range items {
+			item.done <- batchErr.Errors[i]
+		}
+		return
+	}
+	for _, item := range items {
+		item.done <- err
+	}
+}
+
+// batchConcurrency returns the amount of messages of the queue that should be handled at the same time
+// so that the batches of its routes can fill up
+func batchConcurrency(qCfg *config.QueueConfig) int {
+	n := 1
+	for _, rCfg := range qCfg.Routes {
+		if rCfg.Batch != nil && rCfg.Batch.Size > n {
+			n = rCfg.Batch.Size
+		}
+		if rCfg.CircuitBreaker != nil && rCfg.CircuitBreaker.Fallback != nil {
+			if fb := rCfg.CircuitBreaker.Fallback.Batch; fb != nil && fb.Size > n {
+				n = fb.Size
+			}
+		}
+	}
+	for _, dbRoute := range qCfg.DatabaseRoutes {
+		if dbRoute.Batch != nil && dbRoute.Batch.Size > n {
+			n = dbRoute.Batch.Size
+		}
+	}
+	return n
+}
+
+// batchedRequest is the body and the headers of a message in the batch of a route
+type batchedRequest struct {
+	body    []byte
+	headers map[string]string
+}
+
+// sendBatchedRoute adds the body to the batch of the route, which is sent as a JSON array. The batch is sent with
+// the headers of the route and signed like any other request, and the idempotency header, if any, holds the
+// comma separated message IDs of the batch
+func sendBatchedRoute(qCfg *config.QueueConfig, rCfg *config.RouteConfig, body []byte, headers map[string]string, mCfg *config.MetricsConfig) error {
+	b := batcherFor(rCfg, rCfg.Batch, func(items []interface{}) error {
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		requests := make([]batchedRequest, len(items))
+		for i, item := range items {
+			requests[i] = item.(batchedRequest)
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(requests[i].body)
+		}
+		buf.WriteByte(']')
+		_, err := sendRoute(qCfg, rCfg, buf.Bytes(), batchHeaders(qCfg, rCfg, requests), mCfg)
+		return err
+	})
+	return b.add(batchedRequest{body: body, headers: headers})
+}
+
+// batchHeaders returns the headers of a batch, which are the headers of the route and the idempotency
+// header with the IDs of the messages of the batch
+func batchHeaders(qCfg *config.QueueConfig, rCfg *config.RouteConfig, requests []batchedRequest) map[string]string {
+	if qCfg.Dedup == nil || qCfg.Dedup.IdempotencyHeader == "" {
+		return rCfg.Headers
+	}
+	var ids []string
+	for _, request := range requests {
+		if id, ok := request.headers[qCfg.Dedup.IdempotencyHeader]; ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return rCfg.Headers
+	}
+	headers := make(map[string]string, len(rCfg.Headers)+1)
+	for k, v := range rCfg.Headers {
+		headers[k] = v
+	}
+	headers[qCfg.Dedup.IdempotencyHeader] = strings.Join(ids, ",")
+	return headers
+}
+
+// insertBatched adds the data to the batch of the database route, which is inserted at once
+// if the database supports it and row by row otherwise
+func insertBatched(db database.Database, dbRoute *config.DatabaseRouteConfig, data map[string]interface{}) error {
+	b := batcherFor(dbRoute, dbRoute.Batch, func(items []interface{}) error {
+		rows := make([]map[string]interface{}, len(items))
+		for i, item := range items {
+			rows[i] = item.(map[string]interface{})
+		}
+		if inserter, ok := db.(database.BatchInserter); ok {
+			return inserter.InsertMany(rows, *dbRoute)
+		}
+		for _, row := range rows {
+			if err := db.Insert(row, *dbRoute); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return b.add(data)
+}
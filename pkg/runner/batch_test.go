@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
+
+	"github.com/jarcoal/httpmock"
+)
+
+type batchDatabase struct {
+	mu      sync.Mutex
+	inserts int
+	batches [][]map[string]interface{}
+}
+
+func (d *batchDatabase) Connect(connectionString, dbName string) error { return nil }
+
+func (d *batchDatabase) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inserts++
+	return nil
+}
+
+func (d *batchDatabase) InsertMany(data []map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.batches = append(d.batches, data)
+	return nil
+}
+
+func (d *batchDatabase) Close() error { return nil }
+
+func TestBatcher_FlushWhenFull(t *testing.T) {
+	var writes [][]interface{}
+	b := newBatcher(&config.BatchConfig{Size: 3, Wait: time.Hour}, func(items []interface{}) error {
+		writes = append(writes, items)
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := b.add(i); err != nil {
+				t.Errorf("add() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(writes) != 1 || len(writes[0]) != 3 {
+		t.Errorf("expected a single write of 3 items, got %v", writes)
+	}
+}
+
+func TestBatcher_FlushAfterWait(t *testing.T) {
+	var writes [][]interface{}
+	b := newBatcher(&config.BatchConfig{Size: 10, Wait: 20 * time.Millisecond}, func(items []interface{}) error {
+		writes = append(writes, items)
+		return nil
+	})
+
+	start := time.Now()
+	if err := b.add("a"); err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected add to wait for the batch, returned after %s", elapsed)
+	}
+	if len(writes) != 1 || len(writes[0]) != 1 {
+		t.Errorf("expected a single write of 1 item, got %v", writes)
+	}
+}
+
+func TestBatcher_PartialFailure(t *testing.T) {
+	rowErr := errors.New("mapping error")
+	b := newBatcher(&config.BatchConfig{Size: 2, Wait: time.Hour}, func(items []interface{}) error {
+		errs := make([]error, len(items))
+		for i, item := range items {
+			if item == "bad" {
+				errs[i] = rowErr
+			}
+		}
+		return &database.BatchError{Errors: errs}
+	})
+
+	results := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, value := range []string{"good", "bad"} {
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			err := b.add(value)
+			mu.Lock()
+			results[value] = err
+			mu.Unlock()
+		}(value)
+	}
+	wg.Wait()
+
+	if results["good"] != nil || !errors.Is(results["bad"], rowErr) {
+		t.Errorf("expected only the bad item to fail, got %v", results)
+	}
+}
+
+func TestInsertBatched(t *testing.T) {
+	defer flushBatchers()
+
+	db := &batchDatabase{}
+	dbRoute := &config.DatabaseRouteConfig{
+		Name:    "test",
+		Mapping: map[string]string{"name": "name"},
+		Batch:   &config.BatchConfig{Size: 2, Wait: time.Hour},
+	}
+
+	var wg sync.WaitGroup
+	for _, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := insertBatched(db, dbRoute, map[string]interface{}{"name": name}); err != nil {
+				t.Errorf("insertBatched() error = %v", err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if len(db.batches) != 1 || len(db.batches[0]) != 2 || db.inserts != 0 {
+		t.Errorf("expected a single batch insert of 2 rows, got %d batches and %d inserts", len(db.batches), db.inserts)
+	}
+}
+
+func TestSendBatchedRoute(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	defer flushBatchers()
+
+	var bodies []string
+	var headers []http.Header
+	httpmock.RegisterResponder("POST", "http://localhost/batch",
+		func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+			headers = append(headers, req.Header)
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	qCfg := &config.QueueConfig{Name: "testQueue"}
+	rCfg := &config.RouteConfig{
+		Name:    "batchRoute",
+		Method:  "POST",
+		URL:     "http://localhost/batch",
+		Headers: map[string]string{"X-Route": "batch"},
+		Timeout: time.Second,
+		Batch:   &config.BatchConfig{Size: 5, Wait: 20 * time.Millisecond},
+	}
+
+	if err := sendBatchedRoute(qCfg, rCfg, []byte(`{"id":1}`), rCfg.Headers, nil); err != nil {
+		t.Fatalf("sendBatchedRoute() error = %v", err)
+	}
+	if len(bodies) != 1 || bodies[0] != `[{"id":1}]` {
+		t.Errorf("expected the batch to be sent as a JSON array, got %v", bodies)
+	}
+	if headers[0].Get("X-Route") != "batch" {
+		t.Errorf("expected the batch to be sent with the headers of the route, got %v", headers[0])
+	}
+
+	dedupCfg := &config.QueueConfig{Name: "dedupQueue", Dedup: &config.DedupConfig{IdempotencyHeader: "Idempotency-Key"}}
+	dedupRoute := &config.RouteConfig{
+		Name:    "dedupBatchRoute",
+		Method:  "POST",
+		URL:     "http://localhost/batch",
+		Headers: map[string]string{"X-Route": "batch"},
+		Timeout: time.Second,
+		Batch:   &config.BatchConfig{Size: 2, Wait: time.Second},
+	}
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			h := map[string]string{"X-Route": "batch", "Idempotency-Key": id}
+			if err := sendBatchedRoute(dedupCfg, dedupRoute, []byte(`{}`), h, nil); err != nil {
+				t.Errorf("sendBatchedRoute() error = %v", err)
+			}
+		}(id)
+	}
+	wg.Wait()
+	if len(headers) != 2 {
+		t.Fatalf("expected a second batch to be sent, got %d", len(headers))
+	}
+	if key := headers[1].Get("Idempotency-Key"); key != "a,b" && key != "b,a" {
+		t.Errorf("expected the idempotency header to hold the message IDs of the batch, got %q", key)
+	}
+	if headers[1].Get("X-Route") != "batch" {
+		t.Errorf("expected the batch to be sent with the headers of the route, got %v", headers[1])
+	}
+}
+
+func TestBatchConcurrency(t *testing.T) {
+	qCfg := &config.QueueConfig{
+		Routes: []*config.RouteConfig{
+			{Name: "route", Batch: &config.BatchConfig{Size: 20}},
+		},
+		DatabaseRoutes: []*config.DatabaseRouteConfig{
+			{Name: "db", Batch: &config.BatchConfig{Size: 50}},
+		},
+	}
+	if n := batchConcurrency(qCfg); n != 50 {
+		t.Errorf("batchConcurrency() = %d, expected 50", n)
+	}
+	if n := batchConcurrency(&config.QueueConfig{}); n != 1 {
+		t.Errorf("batchConcurrency() = %d, expected 1", n)
+	}
+}
@@ -0,0 +1,191 @@
+package runner
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/metrics"
+)
+
+// breakerState is the state of a circuit breaker, the values are exported as the circuit breaker gauge
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerClosed:
+		return "closed"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "open"
+	}
+}
+
+// circuitBreaker short-circuits the requests of a route while it is failing
+type circuitBreaker struct {
+	route string
+	cfg   *config.CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       breakerState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[*config.RouteConfig]*circuitBreaker)
+)
+
+// breakerFor returns the circuit breaker of the route, creating it on first use, or nil if the route has none
+func breakerFor(rCfg *config.RouteConfig) *circuitBreaker {
+	if rCfg.CircuitBreaker == nil {
+		return nil
+	}
+
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	cb, ok := breakers[rCfg]
+	if !ok {
+		cb = newCircuitBreaker(rCfg.Name, rCfg.CircuitBreaker)
+		breakers[rCfg] = cb
+	}
+	return cb
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker(route string, cfg *config.CircuitBreakerConfig) *circuitBreaker {
+	cb := &circuitBreaker{route: route, cfg: cfg, windowStart: time.Now()}
+	metrics.CircuitBreakerState.WithLabelValues(route).Set(float64(breakerClosed))
+	return cb
+}
+
+// allow reports whether a request can be sent, moving an open circuit to half-open once the open duration has passed
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerOpen:
+		if time.Since(cb.openedAt) < cb.cfg.OpenDuration {
+			return false
+		}
+		cb.setState(breakerHalfOpen)
+		cb.probes = 1
+		return true
+	case breakerHalfOpen:
+		if cb.probes >= cb.cfg.HalfOpenRequests {
+			return false
+		}
+		cb.probes++
+		return true
+	default:
+		return true
+	}
+}
+
+// record records the result of a request that was allowed by the circuit breaker
+func (cb *circuitBreaker) record(success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case breakerHalfOpen:
+		if !success {
+			cb.open()
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.cfg.HalfOpenRequests {
+			cb.setState(breakerClosed)
+			cb.resetWindow()
+		}
+	case breakerClosed:
+		if time.Since(cb.windowStart) > cb.cfg.Window {
+			cb.resetWindow()
+		}
+		cb.requests++
+		if !success {
+			cb.failures++
+		}
+		if cb.requests >= cb.cfg.MinRequests &&
+			float64(cb.failures)/float64(cb.requests) >= cb.cfg.FailureRatio {
+			cb.open()
+		}
+	}
+}
+
+// release gives back a probe that was allowed but not sent
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == breakerHalfOpen && cb.probes > 0 {
+		cb.probes--
+	}
+}
+
+// retryAfter returns the time left until the circuit can be probed again
+func (cb *circuitBreaker) retryAfter() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != breakerOpen {
+		return 0
+	}
+	return cb.cfg.OpenDuration - time.Since(cb.openedAt)
+}
+
+func (cb *circuitBreaker) open() {
+	cb.setState(breakerOpen)
+	cb.openedAt = time.Now()
+	cb.probes = 0
+	cb.successes = 0
+}
+
+func (cb *circuitBreaker) resetWindow() {
+	cb.windowStart = time.Now()
+	cb.requests = 0
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+}
+
+func (cb *circuitBreaker) setState(state breakerState) {
+	if cb.state != state {
+		slog.Info("Circuit breaker state changed", "route", cb.route, "from", cb.state.String(), "to", state.String())
+	}
+	cb.state = state
+	metrics.CircuitBreakerState.WithLabelValues(cb.route).Set(float64(state))
+}
+
+// awaitCircuit returns the route that the request should be sent to. While the circuit is open the fallback
+// route is returned if it is defined, otherwise it blocks until the circuit can be probed, which pauses the consumption
+func awaitCircuit(cb *circuitBreaker, rCfg *config.RouteConfig) *config.RouteConfig {
+	for !cb.allow() {
+		if rCfg.CircuitBreaker.Fallback != nil {
+			slog.Warn("Circuit is open, sending to fallback route", "route", rCfg.Name, "fallback", rCfg.CircuitBreaker.Fallback.Name)
+			return rCfg.CircuitBreaker.Fallback
+		}
+		wait := cb.retryAfter()
+		if wait <= 0 {
+			wait = 100 * time.Millisecond
+		}
+		slog.Warn("Circuit is open, pausing consumption", "route", rCfg.Name, "wait", wait)
+		time.Sleep(wait)
+	}
+	return rCfg
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	cb := newCircuitBreaker("test-route", &config.CircuitBreakerConfig{
+		FailureRatio:     0.5,
+		Window:           time.Minute,
+		MinRequests:      4,
+		OpenDuration:     20 * time.Millisecond,
+		HalfOpenRequests: 2,
+	})
+
+	for _, success := range []bool{true, false, true} {
+		if !cb.allow() {
+			t.Fatal("expected closed circuit to allow requests")
+		}
+		cb.record(success)
+	}
+	if cb.state != breakerClosed {
+		t.Fatalf("expected circuit to stay closed below min requests, got %s", cb.state)
+	}
+
+	cb.allow()
+	cb.record(false)
+	if cb.state != breakerOpen {
+		t.Fatalf("expected circuit to open when failure ratio is reached, got %s", cb.state)
+	}
+	if cb.allow() {
+		t.Error("expected open circuit to reject requests")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !cb.allow() || cb.state != breakerHalfOpen {
+		t.Fatalf("expected circuit to be half-open after open duration, got %s", cb.state)
+	}
+	cb.record(true)
+	if cb.state != breakerHalfOpen {
+		t.Fatalf("expected circuit to need two successful probes, got %s", cb.state)
+	}
+	cb.allow()
+	cb.record(true)
+	if cb.state != breakerClosed {
+		t.Fatalf("expected circuit to close after successful probes, got %s", cb.state)
+	}
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	cb := newCircuitBreaker("test-route", &config.CircuitBreakerConfig{
+		FailureRatio:     1,
+		Window:           time.Minute,
+		MinRequests:      1,
+		OpenDuration:     10 * time.Millisecond,
+		HalfOpenRequests: 1,
+	})
+	cb.allow()
+	cb.record(false)
+	time.Sleep(15 * time.Millisecond)
+
+	if !cb.allow() {
+		t.Fatal("expected a probe to be allowed")
+	}
+	if cb.allow() {
+		t.Error("expected only one probe to be allowed at a time")
+	}
+	cb.record(false)
+	if cb.state != breakerOpen {
+		t.Errorf("expected failed probe to open the circuit, got %s", cb.state)
+	}
+}
+
+func TestHandleRoutes_CircuitBreakerFallback(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost/primary", httpmock.NewStringResponder(503, `down`))
+	httpmock.RegisterResponder("POST", "http://localhost/fallback", httpmock.NewStringResponder(200, `ok`))
+
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Routes: []*config.RouteConfig{
+			{
+				Name:   "primary",
+				Method: "POST",
+				URL:    "http://localhost/primary",
+				CircuitBreaker: &config.CircuitBreakerConfig{
+					FailureRatio:     0.5,
+					Window:           time.Minute,
+					MinRequests:      2,
+					OpenDuration:     time.Minute,
+					HalfOpenRequests: 1,
+					Fallback:         &config.RouteConfig{Name: "fallback", Method: "POST", URL: "http://localhost/fallback"},
+				},
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := handleRoutes(qCfg, map[string]interface{}{}, []byte(`{}`), "", nil); err == nil {
+			t.Fatal("expected an error while the primary route is failing")
+		}
+	}
+	if _, err := handleRoutes(qCfg, map[string]interface{}{}, []byte(`{}`), "", nil); err != nil {
+		t.Fatalf("expected the fallback route to succeed, got %v", err)
+	}
+
+	info := httpmock.GetCallCountInfo()
+	if info["POST http://localhost/primary"] != 2 || info["POST http://localhost/fallback"] != 1 {
+		t.Errorf("unexpected call counts: %v", info)
+	}
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/dedup"
+)
+
+var (
+	dedupStoresMu sync.Mutex
+	dedupStores   = make(map[*config.QueueConfig]dedup.Store)
+)
+
+// dedupStoreFor returns the dedup store of the queue, creating it on first use
+func dedupStoreFor(qCfg *config.QueueConfig, databases []*config.DatabaseConfig) (dedup.Store, error) {
+	dedupStoresMu.Lock()
+	defer dedupStoresMu.Unlock()
+
+	if store, ok := dedupStores[qCfg]; ok {
+		return store, nil
+	}
+	store, err := dedup.NewStore(qCfg.Dedup, databases)
+	if err != nil {
+		return nil, err
+	}
+	dedupStores[qCfg] = store
+	return store, nil
+}
+
+// closeDedupStores closes the dedup stores of all queues
+func closeDedupStores() {
+	dedupStoresMu.Lock()
+	defer dedupStoresMu.Unlock()
+
+	for qCfg, store := range dedupStores {
+		if err := store.Close(); err != nil {
+			slog.Error("Failed to close dedup store", "queue", qCfg.Name, "error", err)
+		}
+		delete(dedupStores, qCfg)
+	}
+}
+
+// claimMessage returns the ID of the message and whether it is a duplicate, which is when it has already been
+// processed or is being processed by another consumer. Otherwise the ID is claimed, and must be marked or released
+// once the message is processed. Errors of the store are logged and the message is processed, so that an unavailable
+// store does not block the queue
+func claimMessage(
+	qCfg *config.QueueConfig,
+	messageData map[string]interface{},
+	msg []byte, metadata map[string]string,
+) (dedup.Store, string, bool) {
+	id, err := dedup.MessageID(qCfg.Dedup, messageData, msg, metadata)
+	if err != nil {
+		slog.Warn("Failed to get message ID, processing without dedup", "queue", qCfg.Name, "error", err)
+		return nil, "", false
+	}
+	store, err := dedupStoreFor(qCfg, nil)
+	if err != nil {
+		slog.Error("Failed to get dedup store", "queue", qCfg.Name, "error", err)
+		return nil, id, false
+	}
+	claimed, err := store.Claim(id)
+	if err != nil {
+		slog.Error("Failed to claim message ID", "queue", qCfg.Name, "id", id, "error", err)
+		return nil, id, false
+	}
+	return store, id, !claimed
+}
+
+// finishClaim marks the claimed ID as processed when the message is processed, otherwise it releases the claim
+// so that the redelivery of the message is processed again
+func finishClaim(qCfg *config.QueueConfig, store dedup.Store, id string, processed bool) {
+	if !processed {
+		if err := store.Release(id); err != nil {
+			slog.Error("Failed to release message ID", "queue", qCfg.Name, "id", id, "error", err)
+		}
+		return
+	}
+	if err := store.Mark(id); err != nil {
+		slog.Error("Failed to mark message as processed", "queue", qCfg.Name, "id", id, "error", err)
+	}
+}
+
+// routeHeaders returns the headers of the route, adding the ID of the message in the idempotency header of the queue if it is configured
+func routeHeaders(qCfg *config.QueueConfig, rCfg *config.RouteConfig, messageID string) map[string]string {
+	if qCfg.Dedup == nil || qCfg.Dedup.IdempotencyHeader == "" || messageID == "" {
+		return rCfg.Headers
+	}
+	headers := make(map[string]string, len(rCfg.Headers)+1)
+	for k, v := range rCfg.Headers {
+		headers[k] = v
+	}
+	headers[qCfg.Dedup.IdempotencyHeader] = messageID
+	return headers
+}
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+// checkExpectations checks the response against the expectations of the route, a nil expectation always passes
+func checkExpectations(expect *config.ExpectConfig, resp *routeResponse) error {
+	if expect == nil {
+		return nil
+	}
+
+	if len(expect.Status) > 0 && !statusAccepted(expect.Status, resp.StatusCode) {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	for name, expected := range expect.Headers {
+		values, ok := resp.Headers[http.CanonicalHeaderKey(name)]
+		if !ok || len(values) == 0 {
+			return fmt.Errorf("expected header %s not found in response", name)
+		}
+		if expected != "" && values[0] != expected {
+			return fmt.Errorf("expected header %s to be %s, got %s", name, expected, values[0])
+		}
+	}
+
+	if len(expect.Body) == 0 {
+		return nil
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		return fmt.Errorf("expected a JSON object in response body: %w", err)
+	}
+	for _, assertion := range expect.Body {
+		if err := checkBodyAssertion(assertion, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBodyAssertion checks a single assertion against the response body
+func checkBodyAssertion(assertion *config.BodyAssertion, body map[string]interface{}) error {
+	path := strings.TrimPrefix(assertion.Path, "$.")
+	value, found := util.LookupPath(body, path)
+
+	if assertion.NotEquals != nil && found && jsonEqual(value, assertion.NotEquals) {
+		return fmt.Errorf("expected %s not to be %v", assertion.Path, assertion.NotEquals)
+	}
+	if assertion.NotEquals != nil && assertion.Equals == nil && assertion.Matches == "" {
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("expected %s to be present in response body", assertion.Path)
+	}
+	if assertion.Equals != nil && !jsonEqual(value, assertion.Equals) {
+		return fmt.Errorf("expected %s to be %v, got %v", assertion.Path, assertion.Equals, value)
+	}
+	if assertion.Matches != "" {
+		matched, err := regexp.MatchString(assertion.Matches, fmt.Sprint(value))
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return fmt.Errorf("expected %s to match %s, got %v", assertion.Path, assertion.Matches, value)
+		}
+	}
+	return nil
+}
+
+// shouldRetryExpectation determines whether a failed expectation should be retried
+func shouldRetryExpectation(expect *config.ExpectConfig, retryConfig *config.RetryConfig) bool {
+	if expect == nil || retryConfig == nil || !retryConfig.Enabled {
+		return false
+	}
+	return expect.OnFailure != common.ExpectOnFailureFail
+}
+
+// expectsStatus reports whether the expectations of the route decide which status codes are accepted,
+// in which case the retry threshold and the failure of 5xx responses do not apply to the route
+func expectsStatus(expect *config.ExpectConfig) bool {
+	return expect != nil && len(expect.Status) > 0
+}
+
+// statusAccepted reports whether the status code matches any of the accepted specifications
+func statusAccepted(specs []string, statusCode int) bool {
+	for _, spec := range specs {
+		low, high, err := util.ParseStatusRange(spec)
+		if err == nil && statusCode >= low && statusCode <= high {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two values by their JSON representation, so that numbers decoded from YAML and JSON are equal
+func jsonEqual(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
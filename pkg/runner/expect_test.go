@@ -0,0 +1,146 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/common"
+	"github.com/bugrakocabay/konsume/pkg/config"
+)
+
+func TestCheckExpectations(t *testing.T) {
+	resp := &routeResponse{
+		StatusCode: 200,
+		Headers:    http.Header{"Content-Type": []string{"application/json"}, "X-Request-Id": []string{"abc"}},
+		Body:       []byte(`{"ok": true, "count": 3, "data": {"id": "user-1"}}`),
+	}
+
+	tests := []struct {
+		name        string
+		expect      *config.ExpectConfig
+		expectError bool
+	}{
+		{name: "nil expectation passes", expect: nil},
+		{name: "status code matches", expect: &config.ExpectConfig{Status: []string{"201", "200"}}},
+		{name: "status class matches", expect: &config.ExpectConfig{Status: []string{"2xx"}}},
+		{name: "status range does not match", expect: &config.ExpectConfig{Status: []string{"201-299"}}, expectError: true},
+		{name: "header is present", expect: &config.ExpectConfig{Headers: map[string]string{"x-request-id": ""}}},
+		{name: "header has value", expect: &config.ExpectConfig{Headers: map[string]string{"Content-Type": "application/json"}}},
+		{name: "header is missing", expect: &config.ExpectConfig{Headers: map[string]string{"X-Trace": ""}}, expectError: true},
+		{
+			name:   "body value equals",
+			expect: &config.ExpectConfig{Body: []*config.BodyAssertion{{Path: "ok", Equals: true}, {Path: "count", Equals: 3}}},
+		},
+		{
+			name:        "body value does not equal",
+			expect:      &config.ExpectConfig{Body: []*config.BodyAssertion{{Path: "ok", Equals: false}}},
+			expectError: true,
+		},
+		{
+			name:   "body value matches with json path prefix",
+			expect: &config.ExpectConfig{Body: []*config.BodyAssertion{{Path: "$.data.id", Matches: "^user-"}}},
+		},
+		{
+			name:        "body value is missing",
+			expect:      &config.ExpectConfig{Body: []*config.BodyAssertion{{Path: "data.name"}}},
+			expectError: true,
+		},
+		{
+			name:   "body value not equals passes when missing",
+			expect: &config.ExpectConfig{Body: []*config.BodyAssertion{{Path: "error", NotEquals: true}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkExpectations(tt.expect, resp)
+			if (err != nil) != tt.expectError {
+				t.Errorf("checkExpectations() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestSendRequestWithStrategy_Expectations(t *testing.T) {
+	tests := []struct {
+		name          string
+		onFailure     string
+		expectedCalls int
+	}{
+		{name: "should retry when expectation fails and on-failure is retry", onFailure: common.ExpectOnFailureRetry, expectedCalls: 3},
+		{name: "should not retry when expectation fails and on-failure is fail", onFailure: common.ExpectOnFailureFail, expectedCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHTTPRequester := &MockHTTPRequester{
+				MockResponse: &http.Response{
+					StatusCode: 200,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"ok": false}`)),
+				},
+			}
+			qCfg := &config.QueueConfig{
+				Name: "testQueue",
+				Retry: &config.RetryConfig{
+					Enabled:         true,
+					Strategy:        common.RetryStrategyFixed,
+					MaxRetries:      2,
+					Interval:        time.Millisecond,
+					ThresholdStatus: 500,
+				},
+			}
+			rCfg := &config.RouteConfig{
+				Name: "TestRoute",
+				Expect: &config.ExpectConfig{
+					Body:      []*config.BodyAssertion{{Path: "ok", Equals: true}},
+					OnFailure: tt.onFailure,
+				},
+			}
+
+			_, err := sendRequestWithStrategy(qCfg, rCfg, nil, mockHTTPRequester)
+			if err == nil {
+				t.Error("expected an error when the expectation fails")
+			}
+			if mockHTTPRequester.CallCount != tt.expectedCalls {
+				t.Errorf("Expected %d calls to SendRequest, got %d", tt.expectedCalls, mockHTTPRequester.CallCount)
+			}
+		})
+	}
+}
+
+func TestSendRequestWithStrategy_ExpectedServerErrorStatus(t *testing.T) {
+	mockHTTPRequester := &MockHTTPRequester{
+		MockResponse: &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"queued": true}`)),
+		},
+	}
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Retry: &config.RetryConfig{
+			Enabled:         true,
+			Strategy:        common.RetryStrategyFixed,
+			MaxRetries:      2,
+			Interval:        time.Millisecond,
+			ThresholdStatus: 500,
+		},
+	}
+	rCfg := &config.RouteConfig{
+		Name:   "TestRoute",
+		Expect: &config.ExpectConfig{Status: []string{"2xx", "503"}},
+	}
+
+	resp, err := sendRequestWithStrategy(qCfg, rCfg, nil, mockHTTPRequester)
+	if err != nil {
+		t.Fatalf("expected an accepted 503 to succeed, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503 response, got %d", resp.StatusCode)
+	}
+	if mockHTTPRequester.CallCount != 1 {
+		t.Errorf("Expected 1 call to SendRequest, got %d", mockHTTPRequester.CallCount)
+	}
+}
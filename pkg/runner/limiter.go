@@ -0,0 +1,156 @@
+package runner
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/requester"
+)
+
+// rateLimiter is a token bucket that blocks until a request is allowed to be sent
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a rateLimiter with a full bucket
+func newRateLimiter(cfg *config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		rate:   cfg.Rate,
+		burst:  float64(cfg.Burst),
+		tokens: float64(cfg.Burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available and takes it
+func (l *rateLimiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		// the token is reserved now, so that the waiting callers are served in order
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// limits holds the rate limiter and the in-flight semaphore of a route or a queue
+type limits struct {
+	limiter  *rateLimiter
+	inFlight chan struct{}
+}
+
+var (
+	limitsMu sync.Mutex
+	limitsOf = make(map[interface{}]*limits)
+)
+
+// limitsFor returns the limits registered for the given key, creating them on first use, or nil if there are none
+func limitsFor(key interface{}, rateLimit *config.RateLimitConfig, maxInFlight int) *limits {
+	if rateLimit == nil && maxInFlight == 0 {
+		return nil
+	}
+
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+
+	l, ok := limitsOf[key]
+	if !ok {
+		l = &limits{}
+		if rateLimit != nil {
+			l.limiter = newRateLimiter(rateLimit)
+		}
+		if maxInFlight > 0 {
+			l.inFlight = make(chan struct{}, maxInFlight)
+		}
+		limitsOf[key] = l
+	}
+	return l
+}
+
+// acquire blocks until the request is allowed by the limits and returns a function that releases the in-flight slot
+func (l *limits) acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+	if l.inFlight != nil {
+		l.inFlight <- struct{}{}
+	}
+	if l.limiter != nil {
+		l.limiter.wait()
+	}
+	return func() {
+		if l.inFlight != nil {
+			<-l.inFlight
+		}
+	}
+}
+
+// limitedRequester applies the limits of the queue and the route to every request including retries,
+// blocking the consumer instead of dropping messages when the limits are reached
+type limitedRequester struct {
+	requester.HTTPRequester
+	queueLimits *limits
+	routeLimits *limits
+}
+
+// withLimits wraps the requester with the limits of the queue and the route, if any
+func withLimits(rqstr requester.HTTPRequester, qCfg *config.QueueConfig, rCfg *config.RouteConfig) requester.HTTPRequester {
+	queueLimits := limitsFor(qCfg, qCfg.RateLimit, qCfg.MaxInFlight)
+	routeLimits := limitsFor(rCfg, rCfg.RateLimit, rCfg.MaxInFlight)
+	if queueLimits == nil && routeLimits == nil {
+		return rqstr
+	}
+	return &limitedRequester{HTTPRequester: rqstr, queueLimits: queueLimits, routeLimits: routeLimits}
+}
+
+// SendRequest waits for the queue and route limits before sending the request. The in-flight slots are
+// released when the body of the response is closed, so that connections that are still being read are counted
+func (r *limitedRequester) SendRequest(m *config.MetricsConfig, timeout time.Duration) (*http.Response, error) {
+	releaseQueue := r.queueLimits.acquire()
+	releaseRoute := r.routeLimits.acquire()
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			releaseRoute()
+			releaseQueue()
+		})
+	}
+
+	resp, err := r.HTTPRequester.SendRequest(m, timeout)
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody releases the in-flight slots of a request when its response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/util"
+)
+
+func TestRateLimiter_Wait(t *testing.T) {
+	limiter := newRateLimiter(&config.RateLimitConfig{Rate: 100, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		limiter.wait()
+	}
+	elapsed := time.Since(start)
+
+	// the first two requests use the burst, the remaining four wait 10ms each
+	if elapsed < 35*time.Millisecond {
+		t.Errorf("expected requests to be throttled, took %s", elapsed)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("expected requests to be throttled to the configured rate, took %s", elapsed)
+	}
+}
+
+type concurrencyRequester struct {
+	current int32
+	max     int32
+}
+
+func (c *concurrencyRequester) SendRequest(m *config.MetricsConfig, timeout time.Duration) (*http.Response, error) {
+	n := atomic.AddInt32(&c.current, 1)
+	for {
+		m := atomic.LoadInt32(&c.max)
+		if n <= m || atomic.CompareAndSwapInt32(&c.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	atomic.AddInt32(&c.current, -1)
+	return &http.Response{StatusCode: 200}, nil
+}
+
+func TestWithLimits_MaxInFlight(t *testing.T) {
+	qCfg := &config.QueueConfig{Name: "testQueue"}
+	rCfg := &config.RouteConfig{Name: "testRoute", MaxInFlight: 2}
+	inner := &concurrencyRequester{}
+	rqstr := withLimits(inner, qCfg, rCfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rqstr.SendRequest(nil, time.Second)
+		}()
+	}
+	wg.Wait()
+
+	if inner.max > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", inner.max)
+	}
+}
+
+type bodyRequester struct{}
+
+func (bodyRequester) SendRequest(m *config.MetricsConfig, timeout time.Duration) (*http.Response, error) {
+	return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+}
+
+func TestWithLimits_ReleasesAfterBodyIsClosed(t *testing.T) {
+	qCfg := &config.QueueConfig{Name: "testQueue"}
+	rCfg := &config.RouteConfig{Name: "testRoute", MaxInFlight: 1}
+	rqstr := withLimits(bodyRequester{}, qCfg, rCfg)
+
+	resp, err := rqstr.SendRequest(nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan struct{})
+	go func() {
+		second, err := rqstr.SendRequest(nil, time.Second)
+		if err == nil {
+			second.Body.Close()
+		}
+		close(sent)
+	}()
+
+	select {
+	case <-sent:
+		t.Fatal("expected the second request to wait until the body of the first response is closed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err = util.ReadRequestBody(resp); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("expected the second request to be sent after the body of the first response is closed")
+	}
+}
+
+func TestWithLimits_NoLimits(t *testing.T) {
+	inner := &MockHTTPRequester{}
+	rqstr := withLimits(inner, &config.QueueConfig{}, &config.RouteConfig{})
+	if rqstr != inner {
+		t.Error("expected requester not to be wrapped when no limits are configured")
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"log/slog"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/database/statement"
+	"github.com/bugrakocabay/konsume/pkg/dedup"
+	"github.com/bugrakocabay/konsume/pkg/message"
 	"github.com/bugrakocabay/konsume/pkg/metrics"
 	"github.com/bugrakocabay/konsume/pkg/queue"
 	"github.com/bugrakocabay/konsume/pkg/requester"
@@ -24,86 +28,319 @@ func listenAndProcess(
 	mCfg *config.MetricsConfig,
 	databases map[string]database.Database,
 ) error {
-	return consumer.Consume(qCfg.Name, func(msg []byte) error {
+	if n := batchConcurrency(qCfg); n > 1 {
+		if cc, ok := consumer.(queue.ConcurrentConsumer); ok {
+			cc.SetConcurrency(n)
+		} else {
+			slog.Warn("Consumer can not handle messages concurrently, batches will be written when their wait elapses", "queue", qCfg.Name)
+		}
+	}
+	handler := func(msg []byte, metadata map[string]string) error {
 		slog.Info("Received a message", "queue", qCfg.Name, "message", string(msg))
-		err := processMessage(msg, qCfg, mCfg, databases)
+		err := processMessage(msg, metadata, qCfg, mCfg, databases)
 		if err != nil {
 			return err
 		}
 
 		return nil
+	}
+	if mc, ok := consumer.(queue.MetadataConsumer); ok {
+		return mc.ConsumeWithMetadata(qCfg.Name, handler)
+	}
+	return consumer.Consume(qCfg.Name, func(msg []byte) error {
+		return handler(msg, nil)
 	})
 }
 
-// processMessage processes the message by sending requests and inserting data into databases
+// processMessage processes the message by sending requests and inserting data into databases,
+// metadata is the delivery metadata of the message given by the consumer, it may be nil
 func processMessage(
-	msg []byte, qCfg *config.QueueConfig,
+	msg []byte, metadata map[string]string,
+	qCfg *config.QueueConfig,
 	mCfg *config.MetricsConfig,
 	databases map[string]database.Database,
 ) error {
-	messageData, err := util.ParseJSONToMap(msg)
+	messageData, err := message.Decode(qCfg, msg)
 	if err != nil {
 		return err
 	}
-	err = handleRoutes(qCfg, messageData, msg, mCfg)
-	if err != nil {
-		return err
+
+	var (
+		store     dedup.Store
+		messageID string
+	)
+	if qCfg.Dedup != nil {
+		var duplicate bool
+		store, messageID, duplicate = claimMessage(qCfg, messageData, msg, metadata)
+		if duplicate {
+			slog.Info("Skipping already processed message", "queue", qCfg.Name, "id", messageID)
+			metrics.MessagesDuplicated.Inc()
+			return nil
+		}
 	}
-	handleDatabaseRoutes(qCfg, messageData, databases)
 
-	return nil
+	routeResponses, err := handleRoutes(qCfg, messageData, msg, messageID, mCfg)
+	if err == nil {
+		err = handleDatabaseRoutes(qCfg, messageData, routeResponses, databases)
+	}
+
+	if store != nil {
+		finishClaim(qCfg, store, messageID, err == nil)
+	}
+
+	return err
 }
 
-// handleRoutes sends requests to the routes defined in the queue config
+// routeResponse is the response received from a route
+type routeResponse struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// handleRoutes sends requests to the routes defined in the queue config and returns the captured responses,
+// messageID is the dedup ID of the message that is sent in the idempotency header, it is empty when dedup is not configured
 func handleRoutes(qCfg *config.QueueConfig,
 	messageData map[string]interface{},
-	msg []byte, mCfg *config.MetricsConfig,
-) error {
+	msg []byte, messageID string,
+	mCfg *config.MetricsConfig,
+) (map[string]interface{}, error) {
 	if qCfg.Routes == nil {
-		return nil
+		return nil, nil
+	}
+	routeResponses := make(map[string]interface{})
+	templateData := messageData
+	if hasCaptures(qCfg) {
+		templateData = withRouteResponses(messageData, routeResponses)
 	}
+
 	for _, rCfg := range qCfg.Routes {
+		target := rCfg
+		cb := breakerFor(rCfg)
+		if cb != nil {
+			target = awaitCircuit(cb, rCfg)
+		}
+
 		var body []byte
 		var err error
-		if len(rCfg.Body) > 0 {
-			body, err = prepareRequestBody(rCfg, messageData)
+		if len(target.Body) > 0 {
+			body, err = prepareRequestBody(target, templateData)
 			if err != nil {
 				slog.Error("Failed to prepare request body", "error", err)
+				if cb != nil && target == rCfg {
+					cb.release()
+				}
 				continue
 			}
 		} else {
 			body = msg
 		}
-		rCfg.URL = appendQueryParams(rCfg.URL, rCfg.Query)
-		rqstr := requester.NewRequester(rCfg.URL, rCfg.Method, body, rCfg.Headers)
-		err = sendRequestWithStrategy(qCfg, rCfg, mCfg, rqstr)
+		var resp *routeResponse
+		headers := routeHeaders(qCfg, target, messageID)
+		if target.Batch != nil {
+			err = sendBatchedRoute(qCfg, target, body, headers, mCfg)
+		} else {
+			resp, err = sendRoute(qCfg, target, body, headers, mCfg)
+		}
+		if cb != nil && target == rCfg {
+			cb.record(err == nil)
+		}
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if rCfg.Capture != "" && resp != nil {
+			routeResponses[rCfg.Capture] = captureResponse(resp)
 		}
 	}
 
-	return nil
+	return routeResponses, nil
+}
+
+// sendRoute sends the request of the route with the given body and headers
+func sendRoute(qCfg *config.QueueConfig,
+	rCfg *config.RouteConfig,
+	body []byte,
+	headers map[string]string,
+	mCfg *config.MetricsConfig,
+) (*routeResponse, error) {
+	var err error
+	endpoint := appendQueryParams(rCfg.URL, rCfg.Query)
+	rqstr := requester.NewRequester(endpoint, rCfg.Method, body, headers)
+	rqstr.Signing = rCfg.Signing
+	rqstr.Transport, err = requester.TransportFor(rCfg)
+	if err != nil {
+		slog.Error("Failed to create transport", "route", rCfg.Name, "error", err)
+		return nil, err
+	}
+	return sendRequestWithStrategy(qCfg, rCfg, mCfg, withLimits(rqstr, qCfg, rCfg))
+}
+
+// hasCaptures reports whether any route of the queue captures its response
+func hasCaptures(qCfg *config.QueueConfig) bool {
+	for _, rCfg := range qCfg.Routes {
+		if rCfg.Capture != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// withRouteResponses returns a copy of the message data that exposes the captured route responses under the routes key
+func withRouteResponses(messageData map[string]interface{}, routeResponses map[string]interface{}) map[string]interface{} {
+	data := copyMessageData(messageData)
+	data[common.RouteResponsesKey] = routeResponses
+	return data
+}
+
+// copyMessageData returns a shallow copy of the message data
+func copyMessageData(messageData map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(messageData)+1)
+	for k, v := range messageData {
+		data[k] = v
+	}
+	return data
+}
+
+// captureResponse converts the response into a map with status, headers and body, parsing the body if it is JSON
+func captureResponse(resp *routeResponse) map[string]interface{} {
+	headers := make(map[string]interface{}, len(resp.Headers))
+	for k := range resp.Headers {
+		headers[k] = resp.Headers.Get(k)
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		body = string(resp.Body)
+	}
+
+	return map[string]interface{}{
+		"status":  resp.StatusCode,
+		"headers": headers,
+		"body":    body,
+	}
 }
 
-// handleDatabaseRoutes inserts data into the databases defined in the queue config
+// handleDatabaseRoutes inserts data into the databases defined in the queue config and returns an error
+// if a database route fails and its on-error behaviour does not ignore the failure
 func handleDatabaseRoutes(
 	qCfg *config.QueueConfig,
 	messageData map[string]interface{},
+	routeResponses map[string]interface{},
 	databases map[string]database.Database,
-) {
+) error {
 	if qCfg.DatabaseRoutes == nil {
-		return
+		return nil
 	}
 	for _, dbRoute := range qCfg.DatabaseRoutes {
-		db, ok := databases[dbRoute.Provider]
-		if !ok {
-			slog.Error("Database not found", "database", dbRoute.Name)
+		data := databaseRouteData(messageData, routeResponses, dbRoute)
+		err := writeDatabaseRoute(qCfg, dbRoute, data, databases)
+		if err == nil {
 			continue
 		}
-		if err := db.Insert(messageData, *dbRoute); err != nil {
-			slog.Error("Failed to insert data into database", "error", err)
+		if dbRoute.OnError == common.DatabaseRouteOnErrorIgnore {
+			slog.Error("Failed to insert data into database, ignoring the failure", "route", dbRoute.Name, "error", err)
+			continue
 		}
+		return fmt.Errorf("database route %s failed: %w", dbRoute.Name, err)
 	}
+	return nil
+}
+
+// writeDatabaseRoute writes the data with the database route, retrying the write with the retry configuration
+// of the queue if the on-error behaviour of the route is retry
+func writeDatabaseRoute(
+	qCfg *config.QueueConfig,
+	dbRoute *config.DatabaseRouteConfig,
+	data map[string]interface{},
+	databases map[string]database.Database,
+) error {
+	db, ok := databases[dbRoute.Provider]
+	if !ok {
+		slog.Error("Database not found", "database", dbRoute.Name)
+		metrics.DatabaseInsertsFailed.WithLabelValues(dbRoute.Name).Inc()
+		return fmt.Errorf("database not found: %s", dbRoute.Provider)
+	}
+
+	// writes wait for a database that can not be reached until it is available again or the reconnect timeout passes
+	err := database.WaitAvailable(dbRoute.Provider)
+	if err == nil {
+		if dbRoute.Batch != nil {
+			err = insertBatched(db, dbRoute, data)
+		} else {
+			err = db.Insert(data, *dbRoute)
+		}
+	}
+	if err == nil {
+		metrics.DatabaseInsertsSucceeded.WithLabelValues(dbRoute.Name).Inc()
+		return nil
+	}
+	slog.Error("Failed to insert data into database", "route", dbRoute.Name, "error", err)
+	metrics.DatabaseInsertsFailed.WithLabelValues(dbRoute.Name).Inc()
+	database.ReportFailure(dbRoute.Provider)
+
+	if dbRoute.OnError != common.DatabaseRouteOnErrorRetry || qCfg.Retry == nil || !qCfg.Retry.Enabled {
+		return err
+	}
+	// a failed batch is retried row by row, so that one invalid row does not fail the other messages again
+	for i := 1; i <= qCfg.Retry.MaxRetries; i++ {
+		slog.Info("Retrying database insert", "route", dbRoute.Name, "retry", i)
+		time.Sleep(calculateRetryInterval(qCfg.Retry, i))
+		if err = database.WaitAvailable(dbRoute.Provider); err == nil {
+			if err = db.Insert(data, *dbRoute); err == nil {
+				metrics.DatabaseInsertsSucceeded.WithLabelValues(dbRoute.Name).Inc()
+				return nil
+			}
+		}
+		slog.Error("Failed to insert data into database on retry", "route", dbRoute.Name, "retry", i, "error", err)
+		metrics.DatabaseInsertsFailed.WithLabelValues(dbRoute.Name).Inc()
+		database.ReportFailure(dbRoute.Provider)
+	}
+	return err
+}
+
+// databaseRouteData returns the data to insert for a database route, resolving the mapping keys
+// that refer to captured route responses, such as "routes.createUser.body.id"
+func databaseRouteData(
+	messageData map[string]interface{},
+	routeResponses map[string]interface{},
+	dbRoute *config.DatabaseRouteConfig,
+) map[string]interface{} {
+	if len(routeResponses) == 0 {
+		return messageData
+	}
+	responseData := map[string]interface{}{common.RouteResponsesKey: routeResponses}
+	data := messageData
+	copied := false
+	mappings := []map[string]string{dbRoute.Mapping}
+	for _, stmt := range dbRoute.Statements {
+		mappings = append(mappings, stmt.Mapping)
+	}
+	if dbRoute.Statement != "" {
+		placeholders := make(map[string]string)
+		for _, name := range statement.TemplateNames(dbRoute.Statement) {
+			placeholders[name] = name
+		}
+		mappings = append(mappings, placeholders)
+	}
+	for _, mapping := range mappings {
+		for key := range mapping {
+			// statements of for-each rows refer to the message with the $message. prefix
+			path := strings.TrimPrefix(key, common.ForEachMessagePrefix)
+			if !strings.HasPrefix(path, common.RouteResponsesKey+".") {
+				continue
+			}
+			value, ok := util.LookupPath(responseData, path)
+			if !ok {
+				continue
+			}
+			if !copied {
+				data = copyMessageData(messageData)
+				copied = true
+			}
+			data[path] = value
+		}
+	}
+	return data
 }
 
 // sendRequestWithStrategy attempts to send an HTTP request and retries based on the provided configuration
@@ -111,26 +348,35 @@ func sendRequestWithStrategy(qCfg *config.QueueConfig,
 	rCfg *config.RouteConfig,
 	mCfg *config.MetricsConfig,
 	requester requester.HTTPRequester,
-) error {
+) (*routeResponse, error) {
+	var response *routeResponse
 	resp, err := requester.SendRequest(mCfg, rCfg.Timeout)
 	if err != nil {
 		slog.Error("Error occurred while sending request", "route", rCfg.Name, "error", err)
-		return err
+		return nil, err
 	}
 	if resp != nil {
 		body, err := util.ReadRequestBody(resp)
 		if err != nil {
 			slog.Error("Failed to read response body", "route", rCfg.Name, "error", err)
-			return err
+			return nil, err
 		}
 		slog.Info("Received a response from",
 			"route", rCfg.Name, "status", resp.StatusCode, "response", body)
-		if shouldRetry(resp, qCfg.Retry) {
-			if err = retryRequest(qCfg, rCfg, mCfg, requester); err != nil {
-				return err
+		response = &routeResponse{StatusCode: resp.StatusCode, Headers: resp.Header, Body: body}
+		expectErr := checkExpectations(rCfg.Expect, response)
+		if expectErr != nil {
+			slog.Error("Response did not meet the expectations", "route", rCfg.Name, "error", expectErr)
+		}
+		statusRules := !expectsStatus(rCfg.Expect)
+		if (statusRules && shouldRetry(resp, qCfg.Retry)) || (expectErr != nil && shouldRetryExpectation(rCfg.Expect, qCfg.Retry)) {
+			if response, err = retryRequest(qCfg, rCfg, mCfg, requester); err != nil {
+				return nil, err
 			}
-		} else if resp.StatusCode >= http.StatusInternalServerError {
-			return fmt.Errorf("received status code: %d", resp.StatusCode)
+		} else if expectErr != nil {
+			return nil, expectErr
+		} else if statusRules && resp.StatusCode >= http.StatusInternalServerError {
+			return nil, fmt.Errorf("received status code: %d", resp.StatusCode)
 		}
 	} else {
 		slog.Error("Received an empty response", "route", rCfg.Name)
@@ -138,7 +384,7 @@ func sendRequestWithStrategy(qCfg *config.QueueConfig,
 
 	metrics.MessagesConsumed.Inc()
 
-	return nil
+	return response, nil
 }
 
 // shouldRetry determines whether a request should be retried based on the response and retry configuration
@@ -154,7 +400,7 @@ func retryRequest(qCfg *config.QueueConfig,
 	rCfg *config.RouteConfig,
 	mCfg *config.MetricsConfig,
 	requester requester.HTTPRequester,
-) error {
+) (*routeResponse, error) {
 	for i := 1; i <= qCfg.Retry.MaxRetries; i++ {
 		slog.Info("Retrying request", "route", rCfg.Name, "retry", i)
 		time.Sleep(calculateRetryInterval(qCfg.Retry, i))
@@ -168,15 +414,24 @@ func retryRequest(qCfg *config.QueueConfig,
 				slog.Error("Failed to read response body", "route", rCfg.Name, "error", err)
 			}
 			slog.Info("Received a response from retry", "route", rCfg.Name, "status", resp.StatusCode, "response", body)
-			if !shouldRetry(resp, qCfg.Retry) {
-				return nil
+			if !expectsStatus(rCfg.Expect) && shouldRetry(resp, qCfg.Retry) {
+				continue
+			}
+			response := &routeResponse{StatusCode: resp.StatusCode, Headers: resp.Header, Body: body}
+			if expectErr := checkExpectations(rCfg.Expect, response); expectErr != nil {
+				slog.Error("Response from retry did not meet the expectations", "route", rCfg.Name, "error", expectErr)
+				if !shouldRetryExpectation(rCfg.Expect, qCfg.Retry) {
+					return nil, expectErr
+				}
+				continue
 			}
+			return response, nil
 		} else {
 			slog.Error("Received an empty response from retry", "route", rCfg.Name)
 		}
 	}
 
-	return fmt.Errorf("failed to send request after %d retries", qCfg.Retry.MaxRetries)
+	return nil, fmt.Errorf("failed to send request after %d retries", qCfg.Retry.MaxRetries)
 }
 
 // calculateRetryInterval computes the time to wait before a retry attempt based on the retry strategy
@@ -2,10 +2,15 @@ package runner
 
 import (
 	"errors"
+	"io"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bugrakocabay/konsume/pkg/common"
 	"github.com/bugrakocabay/konsume/pkg/config"
+	"github.com/bugrakocabay/konsume/pkg/database"
 
 	"github.com/jarcoal/httpmock"
 )
@@ -213,3 +218,292 @@ func TestAppendQueryParams_EmptyQueryParams(t *testing.T) {
 		t.Errorf("appendQueryParams failed, urlWithQueryParams should be equal to url")
 	}
 }
+
+func TestHandleRoutes_CaptureAndChain(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost/users",
+		httpmock.NewStringResponder(201, `{"id": 42, "name": "John"}`))
+
+	var secondBody string
+	httpmock.RegisterResponder("POST", "http://localhost/accounts",
+		func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			secondBody = string(b)
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Routes: []*config.RouteConfig{
+			{
+				Name:    "createUser",
+				Method:  "POST",
+				URL:     "http://localhost/users",
+				Body:    map[string]interface{}{"name": "{{name}}"},
+				Capture: "createUser",
+			},
+			{
+				Name:   "createAccount",
+				Method: "POST",
+				URL:    "http://localhost/accounts",
+				Body:   map[string]interface{}{"userId": "{{routes.createUser.body.id}}", "name": "{{name}}"},
+			},
+		},
+	}
+
+	responses, err := handleRoutes(qCfg, map[string]interface{}{"name": "John"}, nil, "", nil)
+	if err != nil {
+		t.Fatalf("handleRoutes() error = %v", err)
+	}
+	if secondBody != `{"name":"John","userId":42}` {
+		t.Errorf("unexpected body for chained route: %s", secondBody)
+	}
+	captured, ok := responses["createUser"].(map[string]interface{})
+	if !ok || captured["status"] != 201 {
+		t.Errorf("expected captured response with status 201, got %v", responses["createUser"])
+	}
+}
+
+func TestDatabaseRouteData(t *testing.T) {
+	messageData := map[string]interface{}{"name": "John"}
+	routeResponses := map[string]interface{}{
+		"createUser": map[string]interface{}{"body": map[string]interface{}{"id": 42.0}},
+	}
+	dbRoute := &config.DatabaseRouteConfig{
+		Mapping: map[string]string{"name": "user_name", "routes.createUser.body.id": "user_id"},
+	}
+
+	data := databaseRouteData(messageData, routeResponses, dbRoute)
+	if data["routes.createUser.body.id"] != 42.0 || data["name"] != "John" {
+		t.Errorf("unexpected database route data: %v", data)
+	}
+	if _, ok := messageData["routes.createUser.body.id"]; ok {
+		t.Error("expected message data not to be modified")
+	}
+}
+
+func TestDatabaseRouteData_Statements(t *testing.T) {
+	routeResponses := map[string]interface{}{
+		"createOrder": map[string]interface{}{"body": map[string]interface{}{"id": 7.0}},
+	}
+	dbRoute := &config.DatabaseRouteConfig{
+		Statements: []*config.DatabaseStatementConfig{
+			{Table: "order_items", ForEach: "items", Mapping: map[string]string{"$message.routes.createOrder.body.id": "order_id"}},
+		},
+	}
+
+	data := databaseRouteData(map[string]interface{}{"items": []interface{}{}}, routeResponses, dbRoute)
+	if data["routes.createOrder.body.id"] != 7.0 {
+		t.Errorf("expected the captured response of the statement mapping, got %v", data)
+	}
+}
+
+func TestProcessMessage_SkipsDuplicates(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	defer closeDedupStores()
+
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Dedup: &config.DedupConfig{
+			Field:             "id",
+			Store:             common.DedupStoreMemory,
+			TTL:               time.Minute,
+			Size:              10,
+			IdempotencyHeader: "Idempotency-Key",
+		},
+		Routes: []*config.RouteConfig{
+			{Name: "testRoute", Method: "POST", URL: "http://localhost/test"},
+		},
+	}
+
+	var keys []string
+	httpmock.RegisterResponder("POST", "http://localhost/test",
+		func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get("Idempotency-Key"))
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	for _, msg := range []string{`{"id":"1"}`, `{"id":"1"}`, `{"id":"2"}`} {
+		if err := processMessage([]byte(msg), nil, qCfg, nil, nil); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+	}
+
+	if strings.Join(keys, ",") != "1,2" {
+		t.Errorf("expected duplicate message to be skipped, got requests with keys %v", keys)
+	}
+}
+
+func TestProcessMessage_SkipsDuplicatesByMetadata(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	defer closeDedupStores()
+
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Dedup: &config.DedupConfig{
+			Source:            common.DedupSourceMetadata,
+			Field:             common.MetadataMessageID,
+			Store:             common.DedupStoreMemory,
+			TTL:               time.Minute,
+			Size:              10,
+			IdempotencyHeader: "Idempotency-Key",
+		},
+		Routes: []*config.RouteConfig{
+			{Name: "testRoute", Method: "POST", URL: "http://localhost/test"},
+		},
+	}
+
+	var keys []string
+	httpmock.RegisterResponder("POST", "http://localhost/test",
+		func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get("Idempotency-Key"))
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	deliveries := []map[string]string{
+		{common.MetadataMessageID: "a"},
+		{common.MetadataMessageID: "a"},
+		{common.MetadataMessageID: "b"},
+	}
+	for _, metadata := range deliveries {
+		if err := processMessage([]byte(`{"name":"John"}`), metadata, qCfg, nil, nil); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+	}
+
+	if strings.Join(keys, ",") != "a,b" {
+		t.Errorf("expected redelivered message to be skipped, got requests with keys %v", keys)
+	}
+}
+
+func TestProcessMessage_ReleasesClaimOnFailure(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	defer closeDedupStores()
+
+	qCfg := &config.QueueConfig{
+		Name: "testQueue",
+		Dedup: &config.DedupConfig{
+			Field: "id",
+			Store: common.DedupStoreMemory,
+			TTL:   time.Minute,
+			Size:  10,
+		},
+		Routes: []*config.RouteConfig{
+			{Name: "testRoute", Method: "POST", URL: "http://localhost/test"},
+		},
+	}
+
+	calls := 0
+	httpmock.RegisterResponder("POST", "http://localhost/test",
+		func(req *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return httpmock.NewStringResponse(500, `error`), nil
+			}
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	if err := processMessage([]byte(`{"id":"1"}`), nil, qCfg, nil, nil); err == nil {
+		t.Fatal("expected processMessage() to fail")
+	}
+	if err := processMessage([]byte(`{"id":"1"}`), nil, qCfg, nil, nil); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the redelivered message to be processed after the failure, got %d requests", calls)
+	}
+}
+
+type flakyDatabase struct {
+	failures int
+	attempts int
+}
+
+func (d *flakyDatabase) Connect(connectionString, dbName string) error { return nil }
+
+func (d *flakyDatabase) Insert(data map[string]interface{}, dbRouteConfig config.DatabaseRouteConfig) error {
+	d.attempts++
+	if d.attempts <= d.failures {
+		return errors.New("insert failed")
+	}
+	return nil
+}
+
+func (d *flakyDatabase) Close() error { return nil }
+
+func TestHandleDatabaseRoutes_OnError(t *testing.T) {
+	retry := &config.RetryConfig{Enabled: true, MaxRetries: 2, Interval: time.Millisecond, Strategy: common.RetryStrategyFixed}
+
+	tests := []struct {
+		name             string
+		onError          string
+		failures         int
+		expectError      bool
+		expectedAttempts int
+	}{
+		{name: "retry succeeds", onError: common.DatabaseRouteOnErrorRetry, failures: 2, expectedAttempts: 3},
+		{name: "retry exhausted", onError: common.DatabaseRouteOnErrorRetry, failures: 5, expectError: true, expectedAttempts: 3},
+		{name: "dead-letter", onError: common.DatabaseRouteOnErrorDeadLetter, failures: 1, expectError: true, expectedAttempts: 1},
+		{name: "ignore", onError: common.DatabaseRouteOnErrorIgnore, failures: 1, expectedAttempts: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db := &flakyDatabase{failures: tc.failures}
+			qCfg := &config.QueueConfig{
+				Name:  "testQueue",
+				Retry: retry,
+				DatabaseRoutes: []*config.DatabaseRouteConfig{
+					{Name: "testRoute", Provider: "testDB", Table: "test", OnError: tc.onError},
+				},
+			}
+
+			err := handleDatabaseRoutes(qCfg, map[string]interface{}{"id": 1}, nil, map[string]database.Database{"testDB": db})
+			if (err != nil) != tc.expectError {
+				t.Errorf("handleDatabaseRoutes() error = %v, expectError %v", err, tc.expectError)
+			}
+			if db.attempts != tc.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tc.expectedAttempts, db.attempts)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_Format(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	qCfg := &config.QueueConfig{
+		Name:   "testQueue",
+		Format: common.MessageFormatXML,
+		Routes: []*config.RouteConfig{
+			{
+				Name:   "testRoute",
+				Method: "POST",
+				URL:    "http://localhost/test",
+				Body:   map[string]interface{}{"id": "{{@id}}", "customer": "{{customer.name}}"},
+			},
+		},
+	}
+
+	var body string
+	httpmock.RegisterResponder("POST", "http://localhost/test",
+		func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			body = string(b)
+			return httpmock.NewStringResponse(200, `ok`), nil
+		})
+
+	msg := `<order id="42"><customer><name>John</name></customer></order>`
+	if err := processMessage([]byte(msg), nil, qCfg, nil, nil); err != nil {
+		t.Fatalf("processMessage() error = %v", err)
+	}
+	if body != `{"customer":"John","id":"42"}` {
+		t.Errorf("unexpected request body %s", body)
+	}
+}
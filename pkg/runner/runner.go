@@ -9,7 +9,9 @@ import (
 
 	"github.com/bugrakocabay/konsume/pkg/config"
 	"github.com/bugrakocabay/konsume/pkg/database"
+	"github.com/bugrakocabay/konsume/pkg/message"
 	"github.com/bugrakocabay/konsume/pkg/queue"
+	"github.com/bugrakocabay/konsume/pkg/requester"
 )
 
 // StartConsumers starts the consumers for all queues
@@ -39,6 +41,18 @@ func StartConsumers(
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
+			if qc.Dedup != nil {
+				if _, err := dedupStoreFor(qc, cfg.Databases); err != nil {
+					slog.Error("Failed to create dedup store", "queue", qc.Name, "error", err)
+					return
+				}
+			}
+			if qc.Schema != nil {
+				if err := message.LoadSchema(qc); err != nil {
+					slog.Error("Failed to load message schema", "queue", qc.Name, "error", err)
+					return
+				}
+			}
 			if err := connectProviderWithRetry(c, pc); err != nil {
 				slog.Error("Failed to connect provider", "queue", qc.Name, "error", err)
 				return
@@ -58,9 +72,13 @@ func StopConsumers(consumers map[string]queue.MessageQueueConsumer, databases ma
 	for _, c := range consumers {
 		c.Close()
 	}
+	flushBatchers()
+	database.StopMonitoring()
 	for _, db := range databases {
 		db.Close()
 	}
+	closeDedupStores()
+	requester.CloseIdleConnections()
 }
 
 // connectProviderWithRetry tries to connect to the queue with the given consumer
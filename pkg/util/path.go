@@ -0,0 +1,44 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LookupPath returns the value at the given dot separated path of the data, such as "user.address.city" or "items.0.id".
+// Keys that contain dots themselves are matched before the path is split.
+func LookupPath(data map[string]interface{}, path string) (interface{}, bool) {
+	if value, ok := data[path]; ok {
+		return value, true
+	}
+
+	parts := strings.Split(path, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		head := strings.Join(parts[:i], ".")
+		value, ok := data[head]
+		if !ok {
+			continue
+		}
+		return lookupValue(value, parts[i:])
+	}
+	return nil, false
+}
+
+// lookupValue walks the remaining parts of a path through nested maps and slices
+func lookupValue(value interface{}, parts []string) (interface{}, bool) {
+	if len(parts) == 0 {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return LookupPath(v, strings.Join(parts, "."))
+	case []interface{}:
+		index, err := strconv.Atoi(parts[0])
+		if err != nil || index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return lookupValue(v[index], parts[1:])
+	default:
+		return nil, false
+	}
+}
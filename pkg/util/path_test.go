@@ -0,0 +1,43 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupPath(t *testing.T) {
+	data := map[string]interface{}{
+		"name":     "John",
+		"dot.key":  "dotted",
+		"user":     map[string]interface{}{"address": map[string]interface{}{"city": "Istanbul"}},
+		"items":    []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+		"response": map[string]interface{}{"body.id": "x"},
+	}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{path: "name", expected: "John", found: true},
+		{path: "dot.key", expected: "dotted", found: true},
+		{path: "user.address.city", expected: "Istanbul", found: true},
+		{path: "items.1.id", expected: 2.0, found: true},
+		{path: "response.body.id", expected: "x", found: true},
+		{path: "items.5.id", found: false},
+		{path: "user.address.zip", found: false},
+		{path: "name.first", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, found := LookupPath(data, tt.path)
+			if found != tt.found {
+				t.Fatalf("LookupPath(%s) found = %v, want %v", tt.path, found, tt.found)
+			}
+			if !reflect.DeepEqual(value, tt.expected) {
+				t.Errorf("LookupPath(%s) = %v, want %v", tt.path, value, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseStatusRange parses a status code specification such as "200", "200-299" or "2xx" into an inclusive range
+func ParseStatusRange(spec string) (int, int, error) {
+	spec = strings.TrimSpace(strings.ToLower(spec))
+	if len(spec) == 3 && strings.HasSuffix(spec, "xx") {
+		class, err := strconv.Atoi(spec[:1])
+		if err != nil || class < 1 || class > 5 {
+			return 0, 0, fmt.Errorf("invalid status class: %s", spec)
+		}
+		return class * 100, class*100 + 99, nil
+	}
+
+	lowStr, highStr, isRange := strings.Cut(spec, "-")
+	low, err := strconv.Atoi(strings.TrimSpace(lowStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid status code: %s", spec)
+	}
+	high := low
+	if isRange {
+		high, err = strconv.Atoi(strings.TrimSpace(highStr))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid status code: %s", spec)
+		}
+	}
+	if low < 100 || high > 599 || low > high {
+		return 0, 0, fmt.Errorf("invalid status range: %s", spec)
+	}
+	return low, high, nil
+}
@@ -0,0 +1,31 @@
+package util
+
+import "testing"
+
+func TestParseStatusRange(t *testing.T) {
+	tests := []struct {
+		spec        string
+		low, high   int
+		expectError bool
+	}{
+		{spec: "200", low: 200, high: 200},
+		{spec: "200-299", low: 200, high: 299},
+		{spec: "4xx", low: 400, high: 499},
+		{spec: "9xx", expectError: true},
+		{spec: "299-200", expectError: true},
+		{spec: "abc", expectError: true},
+		{spec: "700", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			low, high, err := ParseStatusRange(tt.spec)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseStatusRange() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && (low != tt.low || high != tt.high) {
+				t.Errorf("ParseStatusRange() = %d-%d, want %d-%d", low, high, tt.low, tt.high)
+			}
+		})
+	}
+}
@@ -3,9 +3,18 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// datePlaceholderPrefix is the prefix of the placeholders that are replaced with the current UTC time, such as {{date:2006.01.02}}
+const datePlaceholderPrefix = "date:"
+
+// placeholderRegex matches the placeholders in a template, such as {{name}} or {{routes.createUser.body.id}}
+var placeholderRegex = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
 // ProcessTemplate processes the template and returns the processed body
 func ProcessTemplate(template map[string]interface{}, messageData map[string]interface{}) ([]byte, error) {
 	processedBody, err := process(template, messageData)
@@ -30,7 +39,7 @@ func process(template map[string]interface{}, messageData map[string]interface{}
 		case string:
 			if strings.Contains(v, "{{") && strings.Contains(v, "}}") {
 				fieldName := strings.Trim(v, "{}")
-				if value, ok := messageData[fieldName]; ok {
+				if value, ok := LookupPath(messageData, fieldName); ok {
 					processedBody[key] = value
 				} else {
 					return nil, fmt.Errorf("field %s not found in message", fieldName)
@@ -54,25 +63,67 @@ func process(template map[string]interface{}, messageData map[string]interface{}
 
 // ProcessGraphQLTemplate processes the graphql template and returns the processed body
 func ProcessGraphQLTemplate(graphqlTemplate string, messageData map[string]interface{}) (string, error) {
-	processedQuery := graphqlTemplate
+	var err error
+	processedQuery := placeholderRegex.ReplaceAllStringFunc(graphqlTemplate, func(placeholder string) string {
+		key := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := LookupPath(messageData, key)
+		if !ok {
+			return placeholder
+		}
 
-	if strings.Contains(graphqlTemplate, "{{") && strings.Contains(graphqlTemplate, "}}") {
-		for key, value := range messageData {
-			placeholder := fmt.Sprintf("{{%s}}", key)
-			var valueStr string
+		switch v := value.(type) {
+		case string:
+			return fmt.Sprintf("\"%s\"", v)
+		case int, float64, bool:
+			return fmt.Sprintf("%v", v)
+		default:
+			if err == nil {
+				err = fmt.Errorf("unsupported type for key %s", key)
+			}
+			return placeholder
+		}
+	})
+	if err != nil {
+		return "", err
+	}
 
-			switch v := value.(type) {
-			case string:
-				valueStr = fmt.Sprintf("\"%s\"", v)
-			case int, float64, bool:
-				valueStr = fmt.Sprintf("%v", v)
-			default:
-				return "", fmt.Errorf("unsupported type for key %s", key)
+	return processedQuery, nil
+}
+
+// ProcessStringTemplate replaces the placeholders of a string template, such as an index name or a key, with the values in
+// messageData, and the {{date:layout}} placeholders with the current UTC time formatted with the layout
+func ProcessStringTemplate(template string, messageData map[string]interface{}) (string, error) {
+	var err error
+	processed := placeholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		key := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		if layout, ok := strings.CutPrefix(key, datePlaceholderPrefix); ok {
+			return time.Now().UTC().Format(layout)
+		}
+		value, ok := LookupPath(messageData, key)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("field %s not found in message", key)
 			}
+			return placeholder
+		}
 
-			processedQuery = strings.Replace(processedQuery, placeholder, valueStr, -1)
+		switch v := value.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case int, int64, bool:
+			return fmt.Sprintf("%v", v)
+		default:
+			if err == nil {
+				err = fmt.Errorf("unsupported type for key %s", key)
+			}
+			return placeholder
 		}
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return processedQuery, nil
+	return processed, nil
 }
@@ -2,8 +2,10 @@ package util
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestProcessTemplate(t *testing.T) {
@@ -59,3 +61,32 @@ func TestProcessGraphQLTemplate_UnsupportedType(t *testing.T) {
 		t.Errorf("ProcessGraphQLTemplate() expected error, got nil")
 	}
 }
+
+func TestProcessStringTemplate(t *testing.T) {
+	messageData := map[string]interface{}{
+		"type": "click",
+		"user": map[string]interface{}{"id": float64(42)},
+	}
+
+	result, err := ProcessStringTemplate("events-{{type}}-{{ user.id }}-{{date:2006}}", messageData)
+	if err != nil {
+		t.Fatalf("ProcessStringTemplate() error = %v", err)
+	}
+
+	expected := fmt.Sprintf("events-click-42-%d", time.Now().UTC().Year())
+	if result != expected {
+		t.Errorf("ProcessStringTemplate() got = %v, want %v", result, expected)
+	}
+}
+
+func TestProcessStringTemplate_Errors(t *testing.T) {
+	messageData := map[string]interface{}{
+		"tags": []interface{}{"a"},
+	}
+
+	for _, template := range []string{"events-{{type}}", "events-{{tags}}"} {
+		if _, err := ProcessStringTemplate(template, messageData); err == nil {
+			t.Errorf("ProcessStringTemplate(%s) expected error, got nil", template)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+// Package main builds the Redis driver as a Go plugin, which konsume loads when the driver is excluded from its build
+package main
+
+import "github.com/bugrakocabay/konsume/pkg/database/redis"
+
+var Plugin redis.RedisPlugin